@@ -0,0 +1,211 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Maximum number of updates before giving up on an object that keeps being modified concurrently
+const bindingMaxAttempts = 10
+
+// Terraform applies changes in parallel so bindings targeting the same object have to be serialized
+var bindingLocks = &keyedMutex{locks: make(map[string]*sync.Mutex)}
+
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (m *keyedMutex) Lock(key string) func() {
+	m.mutex.Lock()
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.locks[key] = lock
+	}
+	m.mutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// Consul doesn't support check-and-set for ACL tokens and roles so concurrent read-modify-write cycles can silently
+// revert each other. Mutation functions have to be idempotent and return false when the object already reflects the
+// mutation. Every update is confirmed by reading the object back: when its modify index differs from the one returned
+// by the update someone else wrote it in the meantime, so the object is read again and the mutation is re-applied if
+// it was reverted. A write by another client landing between the read and the update of this one cannot be detected
+// though, only writes made through this provider process are fully serialized.
+func mutateToken(client *consul.Client, accessor string, mutate func(*consul.ACLToken) bool) error {
+	unlock := bindingLocks.Lock("token:" + accessor)
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		aclToken, _, err := client.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return classifyACLError(client, err)
+		}
+
+		if !mutate(aclToken) {
+			return nil
+		}
+
+		if attempt > bindingMaxAttempts {
+			return fmt.Errorf("ACL token %q kept being modified concurrently, gave up after %d attempts", accessor, bindingMaxAttempts)
+		}
+
+		updated, _, err := client.ACL().TokenUpdate(aclToken, nil)
+		if err != nil {
+			return classifyACLError(client, err)
+		}
+
+		current, _, err := client.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return classifyACLError(client, err)
+		}
+
+		if current.ModifyIndex == updated.ModifyIndex {
+			return nil
+		}
+
+		log.Printf("[DEBUG] ACL token %q was modified concurrently with its update, retrying (attempt %d)", accessor, attempt)
+	}
+}
+
+// Same as mutateToken but for roles referenced either by IDs or by names
+func mutateRole(client *consul.Client, roleRef string, mutate func(*consul.ACLRole) bool) error {
+	role, err := readRole(client, roleRef)
+	if err != nil {
+		return err
+	}
+
+	if role == nil {
+		return roleNotFoundError(roleRef)
+	}
+
+	// the same role might be referenced by its ID and by its name so locks are keyed by IDs
+	id := role.ID
+
+	unlock := bindingLocks.Lock("role:" + id)
+	defer unlock()
+
+	for attempt := 1; ; attempt++ {
+		role, err = readRoleByID(client, id)
+		if err != nil {
+			return err
+		}
+
+		if role == nil {
+			return roleNotFoundError(roleRef)
+		}
+
+		if !mutate(role) {
+			return nil
+		}
+
+		if attempt > bindingMaxAttempts {
+			return fmt.Errorf("ACL role %q kept being modified concurrently, gave up after %d attempts", roleRef, bindingMaxAttempts)
+		}
+
+		updated, _, err := client.ACL().RoleUpdate(role, nil)
+		if err != nil {
+			return classifyACLError(client, err)
+		}
+
+		current, err := readRoleByID(client, id)
+		if err != nil {
+			return err
+		}
+
+		if current != nil && current.ModifyIndex == updated.ModifyIndex {
+			return nil
+		}
+
+		log.Printf("[DEBUG] ACL role %q was modified concurrently with its update, retrying (attempt %d)", roleRef, attempt)
+	}
+}
+
+func readRoleByID(client *consul.Client, id string) (*consul.ACLRole, error) {
+	role, _, err := client.ACL().RoleRead(id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ACL role %q: %w", id, classifyACLError(client, err))
+	}
+	return role, nil
+}
+
+// Roles are reported missing with an empty response so the error is made up to be told apart with isNotFoundError
+func roleNotFoundError(roleRef string) error {
+	return &aclError{kind: aclErrorNotFound, cause: fmt.Errorf("ACL role %q not found", roleRef)}
+}
+
+// A link to a policy or a role, links read from Consul have both IDs and names while declared ones have either of them
+type objectLink struct {
+	ID   string
+	Name string
+}
+
+func tokenPolicyObjectLinks(links []*consul.ACLTokenPolicyLink) []objectLink {
+	result := make([]objectLink, 0, len(links))
+	for _, link := range links {
+		result = append(result, objectLink(*link))
+	}
+	return result
+}
+
+func tokenRoleObjectLinks(links []*consul.ACLTokenRoleLink) []objectLink {
+	result := make([]objectLink, 0, len(links))
+	for _, link := range links {
+		result = append(result, objectLink(*link))
+	}
+	return result
+}
+
+func rolePolicyObjectLinks(links []*consul.ACLRolePolicyLink) []objectLink {
+	result := make([]objectLink, 0, len(links))
+	for _, link := range links {
+		result = append(result, objectLink(*link))
+	}
+	return result
+}
+
+// Whether current links point to exactly the same objects as the declared ones
+func sameLinks(current, declared []objectLink) bool {
+	if len(current) != len(declared) {
+		return false
+	}
+
+	for _, want := range declared {
+		found := false
+		for _, have := range current {
+			if (want.ID != "" && want.ID == have.ID) || (want.Name != "" && want.Name == have.Name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Whether both lists define the same service identities regardless of their order
+func sameServiceIdentities(current, declared []*consul.ACLServiceIdentity) bool {
+	return reflect.DeepEqual(serviceIdentityKeys(current), serviceIdentityKeys(declared))
+}
+
+func serviceIdentityKeys(identities []*consul.ACLServiceIdentity) []string {
+	result := make([]string, 0, len(identities))
+	for _, identity := range identities {
+		datacenters := append([]string(nil), identity.Datacenters...)
+		sort.Strings(datacenters)
+		result = append(result, identity.ServiceName+":"+strings.Join(datacenters, ","))
+	}
+	sort.Strings(result)
+	return result
+}
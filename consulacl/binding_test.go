@@ -0,0 +1,202 @@
+package consulacl_test
+
+import (
+	"bytes"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const bindingAccessor = "8c2e4a6f-1b3d-4f5a-9e7c-0d2b4f6a8c1e"
+const bindingRole = "test-binding-race"
+
+// racingWriter stands in for another client that reads the same object as the provider and writes its stale copy back
+// right after the provider's update, silently reverting it the way concurrent read-modify-write cycles do in Consul,
+// unless it keeps the update and only touches the object
+type racingWriter struct {
+	fake   *fakeconsul.Server
+	prefix string
+	races  int
+	keep   bool
+
+	mutex   sync.Mutex
+	stale   []byte
+	updates int
+}
+
+func (w *racingWriter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, w.prefix) {
+		w.fake.ServeHTTP(rw, r)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		recorder := httptest.NewRecorder()
+		w.fake.ServeHTTP(recorder, r)
+		w.stale = recorder.Body.Bytes()
+
+		rw.WriteHeader(recorder.Code)
+		_, _ = rw.Write(w.stale)
+		return
+	}
+
+	w.fake.ServeHTTP(rw, r)
+
+	if r.Method == http.MethodPut {
+		w.updates++
+		if w.updates <= w.races {
+			body := w.stale
+			if w.keep {
+				current := httptest.NewRequest(http.MethodGet, r.URL.Path, nil)
+				current.Header.Set("X-Consul-Token", testFakeMasterToken)
+				recorder := httptest.NewRecorder()
+				w.fake.ServeHTTP(recorder, current)
+				body = recorder.Body.Bytes()
+			}
+
+			write := httptest.NewRequest(http.MethodPut, r.URL.Path, bytes.NewReader(body))
+			write.Header.Set("X-Consul-Token", testFakeMasterToken)
+			w.fake.ServeHTTP(httptest.NewRecorder(), write)
+		}
+	}
+}
+
+func TestBindingConcurrentModification(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource string
+		config   map[string]interface{}
+		races    int
+		keep     bool
+		fails    bool
+		updates  int
+	}{
+		{
+			name:     "policy binding",
+			resource: "consulacl_policy_binding",
+			config:   map[string]interface{}{"accessor": bindingAccessor, "policy": "global-management"},
+			updates:  1,
+		},
+		{
+			name:     "policy binding reverted by another client",
+			resource: "consulacl_policy_binding",
+			config:   map[string]interface{}{"accessor": bindingAccessor, "policy": "global-management"},
+			races:    2,
+			updates:  3,
+		},
+		{
+			name:     "policy binding modified by another client without reverting it",
+			resource: "consulacl_policy_binding",
+			config:   map[string]interface{}{"accessor": bindingAccessor, "policy": "global-management"},
+			races:    1,
+			keep:     true,
+			updates:  1,
+		},
+		{
+			name:     "policy binding that keeps being reverted",
+			resource: "consulacl_policy_binding",
+			config:   map[string]interface{}{"accessor": bindingAccessor, "policy": "global-management"},
+			races:    100,
+			fails:    true,
+			updates:  10,
+		},
+		{
+			name:     "role policy binding reverted by another client",
+			resource: "consulacl_role_policy_binding",
+			config:   map[string]interface{}{"role": bindingRole, "policy": "global-management"},
+			races:    2,
+			updates:  3,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := fakeconsul.NewServer(testFakeMasterToken)
+			defer fake.Close()
+
+			client := testBindingClient(t, fake.Address())
+
+			_, _, err := client.ACL().TokenCreate(&consul.ACLToken{AccessorID: bindingAccessor}, nil)
+			if err != nil {
+				t.Fatalf("cannot provision a test token: %s", err)
+			}
+			_, _, err = client.ACL().RoleCreate(&consul.ACLRole{Name: bindingRole}, nil)
+			if err != nil {
+				t.Fatalf("cannot provision a test role: %s", err)
+			}
+
+			writer := &racingWriter{fake: fake, prefix: "/v1/acl/token/", races: tc.races, keep: tc.keep}
+			if _, ok := tc.config["role"]; ok {
+				writer.prefix = "/v1/acl/role/"
+			}
+
+			server := httptest.NewServer(writer)
+			defer server.Close()
+
+			meta := testConfiguredProviderMeta(t, map[string]interface{}{
+				"address": strings.TrimPrefix(server.URL, "http://"),
+				"scheme":  "http",
+				"token":   testFakeMasterToken,
+			})
+
+			res := aclProvider.ResourcesMap[tc.resource]
+			err = res.Create(schema.TestResourceDataRaw(t, res.Schema, tc.config), meta)
+
+			if tc.fails && err == nil {
+				t.Fatalf("expected the binding to fail")
+			}
+			if !tc.fails && err != nil {
+				t.Fatalf("expected the binding to succeed, got: %s", err)
+			}
+			if err != nil && !strings.Contains(err.Error(), "kept being modified concurrently") {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if writer.updates != tc.updates {
+				t.Fatalf("expected %d updates, got %d", tc.updates, writer.updates)
+			}
+
+			if tc.fails {
+				return
+			}
+
+			// the update has to survive the concurrent writes
+			var links int
+			if tc.resource == "consulacl_role_policy_binding" {
+				role, _, err := client.ACL().RoleReadByName(bindingRole, nil)
+				if err != nil {
+					t.Fatalf("cannot read the test role: %s", err)
+				}
+				links = len(role.Policies)
+			} else {
+				aclToken, _, err := client.ACL().TokenRead(bindingAccessor, nil)
+				if err != nil {
+					t.Fatalf("cannot read the test token: %s", err)
+				}
+				links = len(aclToken.Policies)
+			}
+
+			if links != 1 {
+				t.Fatalf("expected the policy to be bound, got %d policy links", links)
+			}
+		})
+	}
+}
+
+func testBindingClient(t *testing.T, address string) *consul.Client {
+	return testConfiguredProviderMeta(t, map[string]interface{}{
+		"address": address,
+		"scheme":  "http",
+		"token":   testFakeMasterToken,
+	}).(*consulacl.Meta).Client
+}
@@ -11,7 +11,73 @@ const FieldRule = "rule"
 const FieldScope = "scope"
 const FieldPrefix = "prefix"
 const FieldPolicy = "policy"
+const FieldIntentions = "intentions"
 
 const FieldDescription = "description"
 const FieldPolicies = "policies"
 const FieldLocal = "local"
+
+const FieldRules = "rules"
+const FieldDatacenters = "datacenters"
+const FieldDatacenter = "datacenter"
+
+const FieldServiceIdentity = "service_identity"
+const FieldServiceName = "service_name"
+
+const FieldRoles = "roles"
+
+const FieldExpirationTTL = "expiration_ttl"
+const FieldExpirationTime = "expiration_time"
+const FieldCreateTime = "create_time"
+const FieldRenewBefore = "renew_before"
+
+const FieldRole = "role"
+
+const FieldPoliciesAuthoritative = "policies_authoritative"
+const FieldRolesAuthoritative = "roles_authoritative"
+
+const FieldKubernetes = "kubernetes"
+const FieldJWT = "jwt"
+const FieldOIDC = "oidc"
+const FieldHost = "host"
+const FieldCACert = "ca_cert"
+const FieldServiceAccountJWT = "service_account_jwt"
+
+const FieldAuthMethod = "auth_method"
+const FieldSelector = "selector"
+const FieldBindType = "bind_type"
+const FieldBindName = "bind_name"
+
+const FieldSourceDocuments = "source_documents"
+const FieldOverrideDocuments = "override_documents"
+const FieldHCL = "hcl"
+const FieldJSON = "json"
+
+const FieldDefaultPolicy = "default_policy"
+const FieldQuery = "query"
+const FieldResource = "resource"
+const FieldSegment = "segment"
+const FieldAccess = "access"
+const FieldAllowed = "allowed"
+const FieldMatchedRule = "matched_rule"
+const FieldAllAllowed = "all_allowed"
+
+const FieldEffectiveRules = "effective_rules"
+
+const FieldServiceIdentities = "service_identities"
+const FieldLegacy = "legacy"
+
+const FieldDescriptionRegex = "description_regex"
+const FieldExpiresWithin = "expires_within"
+const FieldIncludeSecrets = "include_secrets"
+const FieldAccessors = "accessors"
+const FieldTokens = "tokens"
+
+const FieldID = "id"
+const FieldHash = "hash"
+const FieldNameRegex = "name_regex"
+const FieldNames = "names"
+const FieldIDs = "ids"
+
+const FieldWaitForReplication = "wait_for_replication"
+const FieldTimeout = "timeout"
@@ -3,26 +3,112 @@ package consulacl
 import (
 	"fmt"
 	consul "github.com/hashicorp/consul/api"
+	"log"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Meta is passed by the provider to its resources and data sources
+type Meta struct {
+	Client *consul.Client
+	// Datacenter the client sends requests to, empty for the datacenter of the agent
+	Datacenter string
+	// Default for resources that don't configure waiting for the replication on their own
+	WaitForReplication *ReplicationWait
+
+	config  consul.Config
+	mutex   sync.Mutex
+	clients map[string]*consul.Client // by datacenter
+
+	// resolved once when the provider doesn't configure a datacenter
+	agentDatacenter         string
+	agentDatacenterResolved bool
+}
+
+// Returns the datacenter objects are managed in unless they set one on their own, i.e. the configured one or the one
+// of the agent. Tokens that aren't allowed to read the agent fall back to an unknown (empty) datacenter.
+func (m *Meta) defaultDatacenter() (string, error) {
+	if m.Datacenter != "" {
+		return m.Datacenter, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.agentDatacenterResolved {
+		return m.agentDatacenter, nil
+	}
+
+	// same check as Consul does, reading the agent requires "agent:read" that tokens managing ACLs might not have
+	self, err := m.Client.Agent().Self()
+	switch {
+	case err == nil:
+		m.agentDatacenter, _ = self["Config"]["Datacenter"].(string)
+	case strings.Contains(err.Error(), "Permission denied"):
+		log.Printf("[WARN] Cannot read the datacenter of the Consul agent, set the provider's datacenter to avoid that: %s", err)
+	default:
+		return "", fmt.Errorf("error reading the datacenter of the Consul agent: %s", classifyACLError(m.Client, err))
+	}
+
+	m.agentDatacenterResolved = true
+	return m.agentDatacenter, nil
+}
+
+// Returns a client sending requests to the given datacenter, clients are shared by all resources in the datacenter
+func (m *Meta) datacenterClient(dc string) (*consul.Client, error) {
+	if dc == "" || dc == m.Datacenter {
+		return m.Client, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if client, ok := m.clients[dc]; ok {
+		return client, nil
+	}
+
+	config := m.config
+	config.Datacenter = dc
+	client, err := consul.NewClient(&config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consul client for datacenter %q: '%s'", dc, err)
+	}
+
+	if m.clients == nil {
+		m.clients = make(map[string]*consul.Client)
+	}
+	m.clients[dc] = client
+	return client, nil
+}
+
 type Config struct {
 	// Destination
 	Token string `mapstructure:"token"`
 	// // Auth
-	Address string `mapstructure:"address"`
+	Address    string `mapstructure:"address"`
+	Datacenter string `mapstructure:"datacenter"`
 	// TLS
 	Scheme        string `mapstructure:"scheme"`
 	CAFile        string `mapstructure:"ca_file"`
 	CertFile      string `mapstructure:"cert_file"`
 	KeyFile       string `mapstructure:"key_file"`
 	TlsSkipVerify bool   `mapstructure:"tls_skip_verify"`
+	// Retries
+	RetryMaxAttempts int    `mapstructure:"retry_max_attempts"`
+	RetryMaxWait     string `mapstructure:"retry_max_wait"`
+	// Replication
+	WaitForReplication []ReplicationWait `mapstructure:"wait_for_replication"`
 }
 
-func (c *Config) Client() (*consul.Client, error) {
+func (c *Config) ClientConfig() (*consul.Config, error) {
 	config := consul.DefaultConfig()
 	if c.Address != "" {
 		config.Address = c.Address
 	}
+	if c.Datacenter != "" {
+		config.Datacenter = c.Datacenter
+	}
 	if c.Scheme != "" {
 		config.Scheme = c.Scheme
 	}
@@ -39,14 +125,22 @@ func (c *Config) Client() (*consul.Client, error) {
 		return nil, fmt.Errorf("failed to create consul client: '%s'", err)
 	}
 
+	if c.RetryMaxAttempts > 1 {
+		maxWait, err := time.ParseDuration(c.RetryMaxWait)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retry_max_wait: '%s'", err)
+		}
+
+		config.HttpClient.Transport = &retryTransport{
+			next:        config.HttpClient.Transport,
+			maxAttempts: c.RetryMaxAttempts,
+			maxWait:     maxWait,
+		}
+	}
+
 	if c.Token != "" {
 		config.Token = c.Token
 	}
 
-	client, err := consul.NewClient(config)
-
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
+	return config, nil
 }
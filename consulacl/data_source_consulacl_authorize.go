@@ -0,0 +1,162 @@
+package consulacl
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"strings"
+)
+
+func dataSourceConsulAclAuthorize() *schema.Resource {
+	var allResources []string
+	allResources = append(allResources, prefixedScopes...)
+	allResources = append(allResources, policySingletonScopes...)
+	allResources = append(allResources, rules.IntentionResource)
+
+	identities := serviceIdentitySchema()
+	identities.ConflictsWith = []string{FieldAccessor}
+
+	return &schema.Resource{
+		Read: dataSourceConsulAclAuthorizeRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldAccessor: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{FieldPolicies, FieldRoles, FieldServiceIdentity},
+			},
+
+			FieldPolicies: {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{FieldAccessor},
+			},
+
+			FieldRoles: {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{FieldAccessor},
+			},
+
+			FieldServiceIdentity: identities,
+
+			FieldDefaultPolicy: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "deny",
+				ValidateFunc: validation.StringInSlice([]string{"allow", "deny"}, false),
+			},
+
+			FieldQuery: {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldResource: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(allResources, false),
+						},
+						FieldSegment: {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						FieldAccess: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{rules.AccessRead, rules.AccessList, rules.AccessWrite}, false),
+						},
+						FieldAllowed: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						FieldMatchedRule: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			FieldAllAllowed: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
+		},
+	}
+}
+
+func dataSourceConsulAclAuthorizeRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	var collected []rules.Rules
+
+	if accessor := d.Get(FieldAccessor).(string); accessor != "" {
+		token, _, err := client.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return fmt.Errorf("error reading ACL token %q: %s", accessor, classifyACLError(client, err))
+		}
+		collected, err = collectTokenRules(client, d.Get(FieldDatacenter).(string), token)
+		if err != nil {
+			return err
+		}
+	} else {
+		collected, err = collectRules(
+			client,
+			d.Get(FieldDatacenter).(string),
+			interfacesToStrings(d.Get(FieldPolicies).(*schema.Set).List()),
+			interfacesToStrings(d.Get(FieldRoles).(*schema.Set).List()),
+			expandServiceIdentities(d.Get(FieldServiceIdentity).(*schema.Set).List()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	authorizer := rules.NewAuthorizer(d.Get(FieldDefaultPolicy).(string) == "allow", collected...)
+
+	allAllowed := true
+	var queries []map[string]interface{}
+	for i, raw := range d.Get(FieldQuery).([]interface{}) {
+		query := raw.(map[string]interface{})
+
+		decision, err := authorizer.Authorize(query[FieldResource].(string), query[FieldSegment].(string), query[FieldAccess].(string))
+		if err != nil {
+			return fmt.Errorf("query #%d: %s", i, err)
+		}
+
+		// The default policy applies when no rule matches
+		matched := ""
+		if decision.Rule != nil {
+			matched = strings.TrimSuffix(rules.Rules{*decision.Rule}.HCL(), "\n")
+		}
+
+		allAllowed = allAllowed && decision.Allowed
+		queries = append(queries, map[string]interface{}{
+			FieldResource:    query[FieldResource],
+			FieldSegment:     query[FieldSegment],
+			FieldAccess:      query[FieldAccess],
+			FieldAllowed:     decision.Allowed,
+			FieldMatchedRule: matched,
+		})
+	}
+
+	d.SetId(getSHA256(rules.Merge(collected...).HCL()))
+
+	if err = d.Set(FieldQuery, queries); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldQuery, err)
+	}
+	if err = d.Set(FieldAllAllowed, allAllowed); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldAllAllowed, err)
+	}
+
+	return nil
+}
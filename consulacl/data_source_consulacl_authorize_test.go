@@ -0,0 +1,155 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"testing"
+)
+
+const dataSourceAuthorizeServiceIdentityConfig = `
+data "consulacl_authorize" "test" {
+	service_identity {
+		service_name = "web"
+	}
+
+	query {
+		resource = "service"
+		segment = "web"
+		access = "write"
+	}
+	query {
+		resource = "service"
+		segment = "db"
+		access = "write"
+	}
+	query {
+		resource = "key"
+		segment = "app/config"
+		access = "read"
+	}
+	query {
+		resource = "intention"
+		segment = "web"
+		access = "read"
+	}
+}
+
+data "consulacl_authorize" "elsewhere" {
+	datacenter = "dc1"
+
+	service_identity {
+		service_name = "web"
+		datacenters = ["dc2"]
+	}
+
+	query {
+		resource = "service"
+		segment = "web"
+		access = "write"
+	}
+}
+`
+
+// Service identities are expanded offline so no Consul is required
+func TestDataSourceAuthorizeServiceIdentity(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: dataSourceAuthorizeServiceIdentityConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", consulacl.FieldAllAllowed, "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.0.allowed", "true"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.0.matched_rule", `service "web" { policy = "write" }`),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.1.allowed", "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.1.matched_rule", `service_prefix "" { policy = "read" }`),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.2.allowed", "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.2.matched_rule", ""),
+					// services implicitly grant reading their intentions
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.3.allowed", "true"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.3.matched_rule", `service "web" { policy = "write", intentions = "read" }`),
+					// identities don't grant anything outside of their datacenters
+					resource.TestCheckResourceAttr("data.consulacl_authorize.elsewhere", "query.0.allowed", "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.elsewhere", "query.0.matched_rule", ""),
+				),
+			},
+		},
+	})
+}
+
+const dataSourceAuthorizeTokenConfig = `
+resource "consulacl_policy" "app" {
+	name = "test-authorize-app"
+	rules = <<EOF
+key_prefix "app/" { policy = "write" }
+key_prefix "app/secrets/" { policy = "deny" }
+EOF
+}
+
+resource "consulacl_policy" "readonly" {
+	name = "test-authorize-readonly"
+	rules = <<EOF
+key_prefix "" { policy = "read" }
+operator = "read"
+EOF
+}
+
+resource "consulacl_role" "readonly" {
+	name = "test-authorize-readonly"
+	policies = ["${consulacl_policy.readonly.name}"]
+}
+
+resource "consulacl_token14" "test" {
+	description = "Test Authorize"
+	policies = ["${consulacl_policy.app.name}"]
+	roles = ["${consulacl_role.readonly.name}"]
+}
+
+data "consulacl_authorize" "test" {
+	accessor = "${consulacl_token14.test.accessor}"
+
+	query {
+		resource = "key"
+		segment = "app/config"
+		access = "write"
+	}
+	query {
+		resource = "key"
+		segment = "app/secrets/token"
+		access = "read"
+	}
+	query {
+		resource = "key"
+		segment = "other"
+		access = "read"
+	}
+	query {
+		resource = "operator"
+		access = "write"
+	}
+}
+`
+
+func TestIntegrationDataSourceAuthorize(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: dataSourceAuthorizeTokenConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", consulacl.FieldAllAllowed, "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.0.allowed", "true"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.0.matched_rule", `key_prefix "app/" { policy = "write" }`),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.1.allowed", "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.1.matched_rule", `key_prefix "app/secrets/" { policy = "deny" }`),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.2.allowed", "true"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.2.matched_rule", `key_prefix "" { policy = "read" }`),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.3.allowed", "false"),
+					resource.TestCheckResourceAttr("data.consulacl_authorize.test", "query.3.matched_rule", `operator = "read"`),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,121 @@
+package consulacl
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func dataSourceConsulAclPolicies() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulAclPoliciesRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldNameRegex: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+
+			FieldIDs: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldNames: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldPolicies: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldID: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldName: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldDescription: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldRules: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldDatacenters: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						FieldHash: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
+		},
+	}
+}
+
+func dataSourceConsulAclPoliciesRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// already validated by the schema
+	nameRegex := regexp.MustCompile(d.Get(FieldNameRegex).(string))
+
+	entries, _, err := client.ACL().PolicyList(nil)
+	if err != nil {
+		return fmt.Errorf("error listing ACL policies: %s", classifyACLError(client, err))
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	ids := make([]string, 0)
+	names := make([]string, 0)
+	policies := make([]map[string]interface{}, 0)
+	for _, entry := range entries {
+		if !nameRegex.MatchString(entry.Name) {
+			continue
+		}
+
+		// Rules are not included into the listing
+		policy, _, err := client.ACL().PolicyRead(entry.ID, nil)
+		if err != nil {
+			return fmt.Errorf("error reading ACL policy %q: %s", entry.Name, classifyACLError(client, err))
+		}
+
+		ids = append(ids, policy.ID)
+		names = append(names, policy.Name)
+		policies = append(policies, flattenPolicy(policy))
+	}
+
+	d.SetId(getSHA256(strings.Join(ids, "\n")))
+
+	if err = d.Set(FieldIDs, ids); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldIDs, err)
+	}
+	if err = d.Set(FieldNames, names); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldNames, err)
+	}
+	if err = d.Set(FieldPolicies, policies); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package consulacl_test
+
+import (
+	"github.com/hashicorp/terraform/helper/resource"
+	"testing"
+)
+
+const dataSourcePoliciesResources = `
+resource "consulacl_policy" "first" {
+	name = "test-data-source-policies-first"
+	rules = "operator = \"read\""
+}
+
+resource "consulacl_policy" "second" {
+	name = "test-data-source-policies-second"
+	rules = "acl = \"read\""
+}
+`
+
+const dataSourcePoliciesConfig = dataSourcePoliciesResources + `
+data "consulacl_policies" "test" {
+	name_regex = "^test-data-source-policies-"
+}
+`
+
+func TestIntegrationDataSourcePolicies(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				// data sources are refreshed before resources are created so the policies have to exist upfront
+				Config: dataSourcePoliciesResources,
+			},
+			{
+				Config: dataSourcePoliciesConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_policies.test", "names.#", "2"),
+					resource.TestCheckResourceAttr("data.consulacl_policies.test", "names.0", "test-data-source-policies-first"),
+					resource.TestCheckResourceAttr("data.consulacl_policies.test", "names.1", "test-data-source-policies-second"),
+					resource.TestCheckResourceAttrPair("data.consulacl_policies.test", "ids.0", "consulacl_policy.first", "id"),
+					resource.TestCheckResourceAttrPair("data.consulacl_policies.test", "ids.1", "consulacl_policy.second", "id"),
+					resource.TestCheckResourceAttr("data.consulacl_policies.test", "policies.0.rules", "operator = \"read\"\n"),
+					resource.TestCheckResourceAttr("data.consulacl_policies.test", "policies.1.rules", "acl = \"read\"\n"),
+				),
+			},
+		},
+	})
+}
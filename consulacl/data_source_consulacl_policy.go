@@ -0,0 +1,101 @@
+package consulacl
+
+import (
+	"encoding/hex"
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulAclPolicy() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulAclPolicyRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldID: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{FieldName},
+			},
+
+			FieldName: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{FieldID},
+			},
+
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldRules: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldDatacenters: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldHash: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
+		},
+	}
+}
+
+func dataSourceConsulAclPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	reference := d.Get(FieldID).(string)
+	if reference == "" {
+		reference = d.Get(FieldName).(string)
+	}
+	if reference == "" {
+		return fmt.Errorf("one of %q or %q must be set", FieldID, FieldName)
+	}
+
+	policy, err := readPolicy(client, reference)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		return fmt.Errorf("ACL policy %q not found", reference)
+	}
+
+	d.SetId(policy.ID)
+
+	for field, value := range flattenPolicy(policy) {
+		if err = d.Set(field, value); err != nil {
+			return fmt.Errorf("error while setting %q: %s", field, err)
+		}
+	}
+
+	return nil
+}
+
+func flattenPolicy(policy *consul.ACLPolicy) map[string]interface{} {
+	datacenters := policy.Datacenters
+	if datacenters == nil {
+		datacenters = []string{}
+	}
+
+	return map[string]interface{}{
+		FieldID:          policy.ID,
+		FieldName:        policy.Name,
+		FieldDescription: policy.Description,
+		FieldRules:       normalizeRules(policy.Rules),
+		FieldDatacenters: datacenters,
+		FieldHash:        hex.EncodeToString(policy.Hash),
+	}
+}
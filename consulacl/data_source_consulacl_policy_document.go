@@ -0,0 +1,187 @@
+package consulacl
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+)
+
+func dataSourceConsulAclPolicyDocument() *schema.Resource {
+	var allScopes []string
+	allScopes = append(allScopes, prefixedScopes...)
+	allScopes = append(allScopes, policySingletonScopes...)
+
+	return &schema.Resource{
+		Read: dataSourceConsulAclPolicyDocumentRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldSourceDocuments: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldRule: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldScope: {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(allScopes, false),
+						},
+						FieldName: {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						FieldPrefix: {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						FieldPolicy: {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"read", "write", "deny", "list"}, false),
+						},
+						FieldIntentions: {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"read", "write", "deny"}, false),
+						},
+					},
+				},
+			},
+
+			FieldOverrideDocuments: {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldHCL: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldJSON: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// Rules are merged in layers: source documents, then rule blocks, then each of override documents in order.
+// Every layer replaces the rules of the previous ones that target the same resource.
+func dataSourceConsulAclPolicyDocumentRead(d *schema.ResourceData, meta interface{}) error {
+	var source rules.Rules
+	for i, document := range d.Get(FieldSourceDocuments).([]interface{}) {
+		parsed, err := parseDocument(document)
+		if err != nil {
+			return fmt.Errorf("error parsing source document #%d: %s", i, err)
+		}
+		source = append(source, parsed...)
+	}
+
+	merged, err := source.Normalize()
+	if err != nil {
+		return fmt.Errorf("error merging source documents: %s", err)
+	}
+
+	blocks, err := extractDocumentRules(d.Get(FieldRule).([]interface{}))
+	if err != nil {
+		return err
+	}
+	merged = merged.Override(blocks)
+
+	for i, document := range d.Get(FieldOverrideDocuments).([]interface{}) {
+		parsed, err := parseDocument(document)
+		if err != nil {
+			return fmt.Errorf("error parsing override document #%d: %s", i, err)
+		}
+		overrides, err := parsed.Normalize()
+		if err != nil {
+			return fmt.Errorf("error merging override document #%d: %s", i, err)
+		}
+		merged = merged.Override(overrides)
+	}
+
+	encodedJSON, err := merged.JSON()
+	if err != nil {
+		return err
+	}
+	encodedHCL := merged.HCL()
+
+	d.SetId(getSHA256(encodedHCL))
+
+	if err = d.Set(FieldHCL, encodedHCL); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldHCL, err)
+	}
+	if err = d.Set(FieldJSON, encodedJSON); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldJSON, err)
+	}
+
+	return nil
+}
+
+func parseDocument(document interface{}) (rules.Rules, error) {
+	// Empty list elements come through as nil
+	text, _ := document.(string)
+	return rules.Parse(text)
+}
+
+// Converts rule blocks into rules: `name` makes an exact match while `prefix` makes a prefix match
+func extractDocumentRules(rawRules []interface{}) (rules.Rules, error) {
+	var allErrors *multierror.Error
+
+	var result rules.Rules
+	for i, raw := range rawRules {
+		definition := raw.(map[string]interface{})
+
+		rule := rules.Rule{
+			Resource:   definition[FieldScope].(string),
+			Policy:     definition[FieldPolicy].(string),
+			Intentions: definition[FieldIntentions].(string),
+		}
+
+		name := definition[FieldName].(string)
+		prefix := definition[FieldPrefix].(string)
+
+		switch {
+		case name != "" && prefix != "":
+			err := fmt.Errorf("rule #%d: only one of '%s' and '%s' can be set", i, FieldName, FieldPrefix)
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		case !rules.IsPrefixed(rule.Resource) && (name != "" || prefix != ""):
+			err := fmt.Errorf("rule #%d: the '%s' and '%s' fields are not allowed on scope %s", i, FieldName, FieldPrefix, rule.Resource)
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		case rules.IsPrefixed(rule.Resource) && name != "":
+			rule.Segment = name
+		case rules.IsPrefixed(rule.Resource):
+			rule.Segment = prefix
+			rule.Prefix = true
+		}
+
+		if err := rule.Validate(); err != nil {
+			allErrors = multierror.Append(allErrors, fmt.Errorf("rule #%d: %s", i, err))
+			continue
+		}
+
+		result = append(result, rule)
+	}
+
+	if allErrors.ErrorOrNil() != nil {
+		return nil, allErrors
+	}
+
+	normalized, err := result.Normalize()
+	if err != nil {
+		return nil, fmt.Errorf("error merging rules: %s", err)
+	}
+
+	return normalized, nil
+}
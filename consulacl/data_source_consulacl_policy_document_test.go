@@ -0,0 +1,112 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"regexp"
+	"testing"
+)
+
+const dataSourcePolicyDocumentConfig = `
+data "consulacl_policy_document" "test" {
+	source_documents = [
+		<<EOF
+key_prefix "App/" { policy = "read" }
+operator = "read"
+EOF
+		,
+		"{\"service_prefix\": {\"\": {\"policy\": \"read\"}}, \"operator\": \"read\"}",
+	]
+
+	rule {
+		scope = "key"
+		prefix = "App/"
+		policy = "write"
+	}
+	rule {
+		scope = "service"
+		name = "web \"frontend\""
+		policy = "write"
+		intentions = "read"
+	}
+	rule {
+		scope = "acl"
+		policy = "read"
+	}
+
+	override_documents = [
+		"operator = \"deny\"",
+	]
+}
+`
+
+const dataSourcePolicyDocumentHCL = `acl = "read"
+key_prefix "App/" { policy = "write" }
+operator = "deny"
+service "web \"frontend\"" { policy = "write", intentions = "read" }
+service_prefix "" { policy = "read" }
+`
+
+const dataSourcePolicyDocumentJSON = `{
+  "acl": "read",
+  "key_prefix": {
+    "App/": {
+      "policy": "write"
+    }
+  },
+  "operator": "deny",
+  "service": {
+    "web \"frontend\"": {
+      "intentions": "read",
+      "policy": "write"
+    }
+  },
+  "service_prefix": {
+    "": {
+      "policy": "read"
+    }
+  }
+}
+`
+
+const dataSourcePolicyDocumentConflictingConfig = `
+data "consulacl_policy_document" "test" {
+	source_documents = [
+		"operator = \"read\"",
+		"operator = \"write\"",
+	]
+}
+`
+
+const dataSourcePolicyDocumentInvalidRuleConfig = `
+data "consulacl_policy_document" "test" {
+	rule {
+		scope = "operator"
+		prefix = "foo"
+		policy = "read"
+	}
+}
+`
+
+func TestDataSourcePolicyDocument(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: dataSourcePolicyDocumentConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_policy_document.test", consulacl.FieldHCL, dataSourcePolicyDocumentHCL),
+					resource.TestCheckResourceAttr("data.consulacl_policy_document.test", consulacl.FieldJSON, dataSourcePolicyDocumentJSON),
+				),
+			},
+			{
+				Config:      dataSourcePolicyDocumentConflictingConfig,
+				ExpectError: regexp.MustCompile("conflicting definitions of operator"),
+			},
+			{
+				Config:      dataSourcePolicyDocumentInvalidRuleConfig,
+				ExpectError: regexp.MustCompile("not allowed on scope operator"),
+			},
+		},
+	})
+}
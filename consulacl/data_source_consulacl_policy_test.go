@@ -0,0 +1,77 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"regexp"
+	"testing"
+)
+
+const dataSourcePolicyResources = `
+resource "consulacl_policy" "test" {
+	name = "test-data-source-policy"
+	description = "Test Data Source Policy"
+	datacenters = ["dc1"]
+	rules = <<EOF
+key_prefix "app/" { policy = "write" }
+operator = "read"
+EOF
+}
+`
+
+const dataSourcePolicyConfig = dataSourcePolicyResources + `
+data "consulacl_policy" "by_name" {
+	name = "test-data-source-policy"
+}
+
+data "consulacl_policy" "by_id" {
+	id = "${consulacl_policy.test.id}"
+}
+`
+
+const dataSourcePolicyRules = `key_prefix "app/" { policy = "write" }
+operator = "read"
+`
+
+func TestIntegrationDataSourcePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				// data sources are refreshed before resources are created so the policy has to exist upfront
+				Config: dataSourcePolicyResources,
+			},
+			{
+				Config: dataSourcePolicyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.consulacl_policy.by_name", consulacl.FieldID, "consulacl_policy.test", "id"),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_name", consulacl.FieldDescription, "Test Data Source Policy"),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_name", consulacl.FieldRules, dataSourcePolicyRules),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_name", "datacenters.#", "1"),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_name", "datacenters.0", "dc1"),
+					resource.TestCheckResourceAttrSet("data.consulacl_policy.by_name", consulacl.FieldHash),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_id", consulacl.FieldName, "test-data-source-policy"),
+					resource.TestCheckResourceAttr("data.consulacl_policy.by_id", consulacl.FieldRules, dataSourcePolicyRules),
+				),
+			},
+			{
+				Config:      dataSourcePolicyResources + `data "consulacl_policy" "missing" { name = "test-data-source-policy-missing" }`,
+				ExpectError: regexp.MustCompile(`ACL policy "test-data-source-policy-missing" not found`),
+			},
+		},
+	})
+}
+
+func TestDataSourcePolicyReferenceRequired(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data "consulacl_policy" "test" {}`,
+				ExpectError: regexp.MustCompile(`one of "id" or "name" must be set`),
+			},
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package consulacl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func dataSourceConsulAclRole() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulAclRoleRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldName: {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldPolicies: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldServiceIdentities: computedServiceIdentitiesSchema(),
+
+			FieldHash: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldEffectiveRules: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
+		},
+	}
+}
+
+func dataSourceConsulAclRoleRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get(FieldName).(string)
+
+	role, _, err := client.ACL().RoleReadByName(name, nil)
+	if err != nil {
+		return fmt.Errorf("error reading ACL role %q: %s", name, classifyACLError(client, err))
+	}
+	if role == nil {
+		return fmt.Errorf("ACL role %q not found", name)
+	}
+
+	d.SetId(role.ID)
+
+	if err = d.Set(FieldDescription, role.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	policies := make([]string, 0, len(role.Policies))
+	policyIDs := make([]string, 0, len(role.Policies))
+	for _, policyLink := range role.Policies {
+		policies = append(policies, policyLink.Name)
+		policyIDs = append(policyIDs, policyLink.ID)
+	}
+	if err = d.Set(FieldPolicies, policies); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
+	}
+
+	if err = d.Set(FieldServiceIdentities, flattenServiceIdentities(role.ServiceIdentities)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldServiceIdentities, err)
+	}
+
+	if err = d.Set(FieldHash, hex.EncodeToString(role.Hash)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldHash, err)
+	}
+
+	collected, err := collectRules(client, d.Get(FieldDatacenter).(string), policyIDs, nil, role.ServiceIdentities)
+	if err != nil {
+		return fmt.Errorf("error collecting rules of ACL role %q: %s", name, err)
+	}
+	if err = d.Set(FieldEffectiveRules, rules.Merge(collected...).HCL()); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldEffectiveRules, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"regexp"
+	"testing"
+)
+
+const dataSourceRoleResources = `
+resource "consulacl_policy" "test" {
+	name = "test-data-source-role"
+	rules = "operator = \"read\""
+}
+
+resource "consulacl_role" "test" {
+	name = "test-data-source-role"
+	description = "Test Data Source Role"
+	policies = ["${consulacl_policy.test.name}"]
+
+	service_identity {
+		service_name = "web"
+	}
+}
+`
+
+const dataSourceRoleConfig = dataSourceRoleResources + `
+data "consulacl_role" "test" {
+	name = "test-data-source-role"
+}
+`
+
+const dataSourceRoleEffectiveRules = `node_prefix "" { policy = "read" }
+operator = "read"
+service "web" { policy = "write" }
+service "web-sidecar-proxy" { policy = "write" }
+service_prefix "" { policy = "read" }
+`
+
+func TestIntegrationDataSourceRole(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				// data sources are refreshed before resources are created so the role has to exist upfront
+				Config: dataSourceRoleResources,
+			},
+			{
+				Config: dataSourceRoleConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.consulacl_role.test", "id", "consulacl_role.test", "id"),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", consulacl.FieldDescription, "Test Data Source Role"),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", "policies.#", "1"),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", "policies.0", "test-data-source-role"),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", "service_identities.#", "1"),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", "service_identities.0.service_name", "web"),
+					resource.TestCheckResourceAttrSet("data.consulacl_role.test", consulacl.FieldHash),
+					resource.TestCheckResourceAttr("data.consulacl_role.test", consulacl.FieldEffectiveRules, dataSourceRoleEffectiveRules),
+				),
+			},
+			{
+				Config:      dataSourceRoleResources + `data "consulacl_role" "missing" { name = "test-data-source-role-missing" }`,
+				ExpectError: regexp.MustCompile(`ACL role "test-data-source-role-missing" not found`),
+			},
+		},
+	})
+}
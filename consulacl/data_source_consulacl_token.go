@@ -1,8 +1,10 @@
 package consulacl
 
 import (
+	"fmt"
 	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
+	"time"
 )
 
 func dataSourceConsulAclToken() *schema.Resource {
@@ -11,43 +13,285 @@ func dataSourceConsulAclToken() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			FieldAccessor: {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{FieldSecret, FieldDescription},
 			},
 
 			FieldSecret: {
-				Type:      schema.TypeString,
-				Computed:  true,
-				Sensitive: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{FieldAccessor, FieldDescription},
+			},
+
+			FieldDescription: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{FieldAccessor, FieldSecret},
+			},
+
+			FieldPolicies: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldRoles: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldServiceIdentities: computedServiceIdentitiesSchema(),
+
+			FieldLocal: {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			FieldCreateTime: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldExpirationTime: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			FieldLegacy: {
+				Type:     schema.TypeBool,
+				Computed: true,
 			},
+
+			FieldRule: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldScope: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldPrefix: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldPolicy: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldIntentions: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			FieldEffectiveRules: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
 		},
 	}
 }
 
 func dataSourceConsulAclTokenRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	accessor := d.Get(FieldAccessor).(string)
+	secret := d.Get(FieldSecret).(string)
+	description := d.Get(FieldDescription).(string)
 
-	id := d.Get(FieldAccessor).(string)
-	acl, _, err := client.ACL().TokenRead(id, nil)
+	switch {
+	case accessor != "":
+	case secret != "":
+		accessor, err = lookupTokenBySecret(client, secret)
+	case description != "":
+		accessor, err = lookupTokenByDescription(client, description)
+	default:
+		return fmt.Errorf("one of %q, %q or %q must be set", FieldAccessor, FieldSecret, FieldDescription)
+	}
 	if err != nil {
 		return err
 	}
 
+	acl, _, err := client.ACL().TokenRead(accessor, nil)
+	if err != nil {
+		return fmt.Errorf("error reading ACL token %q: %s", accessor, classifyACLError(client, err))
+	}
+
 	if acl == nil {
 		d.SetId("")
 		return nil
 	}
 
-	d.SetId(id)
-	_ = d.Set(FieldSecret, acl.SecretID)
+	d.SetId(acl.AccessorID)
+
+	if err = d.Set(FieldAccessor, acl.AccessorID); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldAccessor, err)
+	}
+
+	if err = d.Set(FieldSecret, acl.SecretID); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldSecret, err)
+	}
+
+	if err = d.Set(FieldDescription, acl.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	if err = d.Set(FieldPolicies, tokenPolicyNames(acl.Policies)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
+	}
+
+	if err = d.Set(FieldRoles, tokenRoleNames(acl.Roles)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldRoles, err)
+	}
+
+	if err = d.Set(FieldServiceIdentities, flattenServiceIdentities(acl.ServiceIdentities)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldServiceIdentities, err)
+	}
+
+	if err = d.Set(FieldLocal, acl.Local); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldLocal, err)
+	}
+
+	if err = d.Set(FieldCreateTime, acl.CreateTime.Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldCreateTime, err)
+	}
 
+	expirationTime := ""
+	if acl.ExpirationTime != nil {
+		expirationTime = acl.ExpirationTime.Format(time.RFC3339)
+	}
+	if err = d.Set(FieldExpirationTime, expirationTime); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldExpirationTime, err)
+	}
+
+	legacy, err := isLegacyToken(client, acl)
+	if err != nil {
+		return err
+	}
+
+	if err = d.Set(FieldLegacy, legacy); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldLegacy, err)
+	}
+
+	// Only legacy tokens have rules of their own
 	rules, err := decodeRules(acl.Rules)
+	if err != nil {
+		return fmt.Errorf("cannot decode rules of ACL token %q: %s", acl.AccessorID, err)
+	}
+	if err = d.Set(FieldRule, rules); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldRule, err)
+	}
+
+	effectiveRules, err := effectiveTokenRules(client, d.Get(FieldDatacenter).(string), acl)
 	if err != nil {
 		return err
 	}
 
-	_ = d.Set(FieldRule, rules)
+	if err = d.Set(FieldEffectiveRules, effectiveRules); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldEffectiveRules, err)
+	}
 
 	return nil
 }
+
+func computedServiceIdentitiesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				FieldServiceName: {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				FieldDatacenters: {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem:     &schema.Schema{Type: schema.TypeString},
+				},
+			},
+		},
+	}
+}
+
+func tokenPolicyNames(links []*consul.ACLTokenPolicyLink) []string {
+	result := make([]string, 0, len(links))
+	for _, link := range links {
+		result = append(result, link.Name)
+	}
+	return result
+}
+
+func tokenRoleNames(links []*consul.ACLTokenRoleLink) []string {
+	result := make([]string, 0, len(links))
+	for _, link := range links {
+		result = append(result, link.Name)
+	}
+	return result
+}
+
+// Consul doesn't expose the type of a token when it's read, only the token listing marks legacy ones. Tokens with
+// rules embedded are legacy ones for sure so the listing is only needed for those without rules.
+func isLegacyToken(client *consul.Client, token *consul.ACLToken) (bool, error) {
+	if token.Rules != "" {
+		return true, nil
+	}
+
+	entries, _, err := client.ACL().TokenList(nil)
+	if err != nil {
+		return false, fmt.Errorf("error listing ACL tokens: %s", classifyACLError(client, err))
+	}
+
+	for _, entry := range entries {
+		if entry.AccessorID == token.AccessorID {
+			return entry.Legacy, nil
+		}
+	}
+
+	return false, nil
+}
+
+// A token can always read itself, so the lookup works for secrets of tokens without the `acl:read` permission
+func lookupTokenBySecret(client *consul.Client, secret string) (string, error) {
+	token, _, err := client.ACL().TokenReadSelf(&consul.QueryOptions{Token: secret})
+	if err != nil {
+		return "", fmt.Errorf("error reading ACL token by its secret: %s", classifyACLError(client, err))
+	}
+	return token.AccessorID, nil
+}
+
+func lookupTokenByDescription(client *consul.Client, description string) (string, error) {
+	entries, _, err := client.ACL().TokenList(nil)
+	if err != nil {
+		return "", fmt.Errorf("error listing ACL tokens: %s", classifyACLError(client, err))
+	}
+
+	var matches []string
+	for _, entry := range entries {
+		if entry.Description == description {
+			matches = append(matches, entry.AccessorID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no ACL token with description %q", description)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("description %q is ambiguous, it matches %d ACL tokens: %v", description, len(matches), matches)
+	}
+}
@@ -10,25 +10,89 @@ import (
 	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/resource"
 	"os"
+	"regexp"
 )
 
 const dataSourceAclTokenAccessor = "65150ab9-1ba8-4538-a1cd-b0f64780ffb6"
 const dataSourceAclTokenSecret = "b7723bf9-cf63-4c69-96bf-dccb924e4734"
+const dataSourceAclTokenDescription = "Test consulacl_token data source"
 const dataSourceAclTokenConfig = `
 data "consulacl_token" "test" {
   accessor = "65150ab9-1ba8-4538-a1cd-b0f64780ffb6"
 }
+
+data "consulacl_token" "by_secret" {
+  secret = "b7723bf9-cf63-4c69-96bf-dccb924e4734"
+}
+
+data "consulacl_token" "by_description" {
+  description = "Test consulacl_token data source"
+}
+`
+
+const dataSourceAclTokenEffectiveRules = `node_prefix "" { policy = "read" }
+service "web" { policy = "write" }
+service "web-sidecar-proxy" { policy = "write" }
+service_prefix "" { policy = "read" }
+`
+
+const dataSourceAclTokenLegacyConfig = `
+resource "consulacl_token" "legacy" {
+  name  = "Test consulacl_token data source legacy"
+  token = "2d3b8c6f-4c7e-4a0e-9a4e-5a0c3f7d1b2e"
+  type  = "client"
+
+  rule {
+    scope  = "key"
+    prefix = "foo/"
+    policy = "write"
+  }
+  rule {
+    scope  = "operator"
+    policy = "read"
+  }
+}
+
+data "consulacl_token" "legacy" {
+  secret = "${consulacl_token.legacy.token}"
+}
+
+resource "consulacl_token" "legacy_without_rules" {
+  name  = "Test consulacl_token data source legacy without rules"
+  token = "7e1f4a9c-2b6d-4e8a-b3c5-9d0e2f4a6b8c"
+  type  = "client"
+}
+
+data "consulacl_token" "legacy_without_rules" {
+  secret = "${consulacl_token.legacy_without_rules.token}"
+}
 `
 
 func TestIntegrationDataSourceToken(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		IsUnitTest: false,
+		IsUnitTest: testAgainstFake,
 		Providers:  testProviders,
 		PreCheck:   func() { testDataSourceConsulAclTokenPreConfig(t) },
 		Steps: []resource.TestStep{
 			{
 				Config: dataSourceAclTokenConfig,
-				Check:  resource.TestCheckResourceAttr("data.consulacl_token.test", "secret", dataSourceAclTokenSecret),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "secret", dataSourceAclTokenSecret),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", consulacl.FieldEffectiveRules, dataSourceAclTokenEffectiveRules),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", consulacl.FieldDescription, dataSourceAclTokenDescription),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "policies.#", "0"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "roles.#", "0"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "service_identities.#", "1"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "service_identities.0.service_name", "web"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", consulacl.FieldLocal, "true"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", consulacl.FieldLegacy, "false"),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", consulacl.FieldExpirationTime, ""),
+					resource.TestCheckResourceAttrSet("data.consulacl_token.test", consulacl.FieldCreateTime),
+					resource.TestCheckResourceAttr("data.consulacl_token.test", "rule.#", "0"),
+					resource.TestCheckResourceAttr("data.consulacl_token.by_secret", consulacl.FieldAccessor, dataSourceAclTokenAccessor),
+					resource.TestCheckResourceAttr("data.consulacl_token.by_description", consulacl.FieldAccessor, dataSourceAclTokenAccessor),
+					resource.TestCheckResourceAttr("data.consulacl_token.by_description", consulacl.FieldSecret, dataSourceAclTokenSecret),
+				),
 			},
 			{
 				Config: "locals {}",
@@ -40,6 +104,43 @@ func TestIntegrationDataSourceToken(t *testing.T) {
 	})
 }
 
+func TestIntegrationDataSourceTokenLegacy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: dataSourceAclTokenLegacyConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", consulacl.FieldLegacy, "true"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", consulacl.FieldDescription, "Test consulacl_token data source legacy"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.#", "2"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.0.scope", "key"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.0.prefix", "foo/"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.0.policy", "write"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.1.scope", "operator"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy", "rule.1.policy", "read"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy_without_rules", consulacl.FieldLegacy, "true"),
+					resource.TestCheckResourceAttr("data.consulacl_token.legacy_without_rules", "rule.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceTokenLookupRequired(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data "consulacl_token" "test" {}`,
+				ExpectError: regexp.MustCompile("one of \"accessor\", \"secret\" or \"description\" must be set"),
+			},
+		},
+	})
+}
+
 func testDataSourceConsulAclTokenPreConfig(t *testing.T) {
 	ok := false
 
@@ -68,8 +169,11 @@ func testDataSourceConsulAclTokenPreConfig(t *testing.T) {
 	}
 
 	token := &consul.ACLToken{
-		AccessorID: dataSourceAclTokenAccessor,
-		SecretID:   dataSourceAclTokenSecret,
+		AccessorID:        dataSourceAclTokenAccessor,
+		SecretID:          dataSourceAclTokenSecret,
+		Description:       dataSourceAclTokenDescription,
+		Local:             true,
+		ServiceIdentities: []*consul.ACLServiceIdentity{{ServiceName: "web"}},
 	}
 
 	_, _, err = testClient.ACL().TokenCreate(token, nil)
@@ -0,0 +1,253 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+func dataSourceConsulAclTokens() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceConsulAclTokensRead,
+
+		Schema: map[string]*schema.Schema{
+			FieldPolicy: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name or ID of a policy linked to the tokens",
+			},
+
+			FieldRole: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name or ID of a role linked to the tokens",
+			},
+
+			FieldDescriptionRegex: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.ValidateRegexp,
+			},
+
+			FieldLocal: {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			FieldLegacy: {
+				Type:     schema.TypeBool,
+				Optional: true,
+			},
+
+			FieldExpiresWithin: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Only match tokens that expire within the given duration from now, e.g. '24h'",
+			},
+
+			FieldIncludeSecrets: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to read secrets of the matching tokens, which takes a request per token",
+			},
+
+			FieldAccessors: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			FieldTokens: {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldAccessor: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldSecret: {
+							Type:      schema.TypeString,
+							Computed:  true,
+							Sensitive: true,
+						},
+						FieldDescription: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldPolicies: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						FieldRoles: {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						FieldServiceIdentities: computedServiceIdentitiesSchema(),
+						FieldLocal: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						FieldLegacy: {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						FieldCreateTime: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						FieldExpirationTime: {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			FieldDatacenter: dataSourceDatacenterSchema(),
+		},
+	}
+}
+
+func dataSourceConsulAclTokensRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	matches, err := tokenListFilter(d)
+	if err != nil {
+		return err
+	}
+
+	entries, _, err := client.ACL().TokenList(nil)
+	if err != nil {
+		return fmt.Errorf("error listing ACL tokens: %s", classifyACLError(client, err))
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AccessorID < entries[j].AccessorID })
+
+	includeSecrets := d.Get(FieldIncludeSecrets).(bool)
+
+	accessors := make([]string, 0)
+	tokens := make([]map[string]interface{}, 0)
+	for _, entry := range entries {
+		if !matches(entry) {
+			continue
+		}
+
+		secret := ""
+		if includeSecrets {
+			token, _, err := client.ACL().TokenRead(entry.AccessorID, nil)
+			if err != nil {
+				return fmt.Errorf("error reading ACL token %q: %s", entry.AccessorID, classifyACLError(client, err))
+			}
+			secret = token.SecretID
+		}
+
+		expirationTime := ""
+		if entry.ExpirationTime != nil {
+			expirationTime = entry.ExpirationTime.Format(time.RFC3339)
+		}
+
+		accessors = append(accessors, entry.AccessorID)
+		tokens = append(tokens, map[string]interface{}{
+			FieldAccessor:          entry.AccessorID,
+			FieldSecret:            secret,
+			FieldDescription:       entry.Description,
+			FieldPolicies:          tokenPolicyNames(entry.Policies),
+			FieldRoles:             tokenRoleNames(entry.Roles),
+			FieldServiceIdentities: flattenServiceIdentities(entry.ServiceIdentities),
+			FieldLocal:             entry.Local,
+			FieldLegacy:            entry.Legacy,
+			FieldCreateTime:        entry.CreateTime.Format(time.RFC3339),
+			FieldExpirationTime:    expirationTime,
+		})
+	}
+
+	d.SetId(getSHA256(strings.Join(accessors, "\n")))
+
+	if err = d.Set(FieldAccessors, accessors); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldAccessors, err)
+	}
+	if err = d.Set(FieldTokens, tokens); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldTokens, err)
+	}
+
+	return nil
+}
+
+// Builds a predicate out of all the configured filters, a token has to pass every one of them
+func tokenListFilter(d *schema.ResourceData) (func(*consul.ACLTokenListEntry) bool, error) {
+	var filters []func(*consul.ACLTokenListEntry) bool
+
+	if policy := d.Get(FieldPolicy).(string); policy != "" {
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			for _, link := range entry.Policies {
+				if link.ID == policy || link.Name == policy {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if role := d.Get(FieldRole).(string); role != "" {
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			for _, link := range entry.Roles {
+				if link.ID == role || link.Name == role {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	if pattern := d.Get(FieldDescriptionRegex).(string); pattern != "" {
+		// already validated by the schema
+		re := regexp.MustCompile(pattern)
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			return re.MatchString(entry.Description)
+		})
+	}
+
+	if local, ok := d.GetOkExists(FieldLocal); ok {
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			return entry.Local == local.(bool)
+		})
+	}
+
+	if legacy, ok := d.GetOkExists(FieldLegacy); ok {
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			return entry.Legacy == legacy.(bool)
+		})
+	}
+
+	if expiresWithin := d.Get(FieldExpiresWithin).(string); expiresWithin != "" {
+		window, err := time.ParseDuration(expiresWithin)
+		if err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(window)
+		filters = append(filters, func(entry *consul.ACLTokenListEntry) bool {
+			return entry.ExpirationTime != nil && !deadline.Before(*entry.ExpirationTime)
+		})
+	}
+
+	return func(entry *consul.ACLTokenListEntry) bool {
+		for _, filter := range filters {
+			if !filter(entry) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
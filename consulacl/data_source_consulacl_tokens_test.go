@@ -0,0 +1,101 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"regexp"
+	"testing"
+)
+
+const dataSourceTokensResources = `
+resource "consulacl_policy" "test" {
+	name = "test-tokens-filter"
+	rules = "operator = \"read\""
+}
+
+resource "consulacl_token14" "first" {
+	accessor = "1a9b3e2c-7f6d-4c1b-8e0a-5d4c3b2a1f00"
+	description = "Test Tokens pipeline-first"
+	policies = ["${consulacl_policy.test.name}"]
+}
+
+resource "consulacl_token14" "second" {
+	accessor = "2b8c4f3d-6e5c-4b0a-9f1e-4c3b2a1f0e11"
+	description = "Test Tokens pipeline-second"
+	expiration_ttl = "1h"
+}
+`
+
+const dataSourceTokensConfig = dataSourceTokensResources + `
+data "consulacl_tokens" "by_policy" {
+	policy = "${consulacl_policy.test.name}"
+	include_secrets = true
+}
+
+data "consulacl_tokens" "by_description" {
+	description_regex = "^Test Tokens pipeline-"
+}
+
+data "consulacl_tokens" "expiring" {
+	description_regex = "^Test Tokens pipeline-"
+	expires_within = "2h"
+}
+
+data "consulacl_tokens" "global" {
+	description_regex = "^Test Tokens pipeline-"
+	local = false
+}
+
+data "consulacl_tokens" "legacy" {
+	description_regex = "^Test Tokens pipeline-"
+	legacy = true
+}
+`
+
+func TestIntegrationDataSourceTokens(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				// data sources are refreshed before resources are created so the tokens have to exist upfront
+				Config: dataSourceTokensResources,
+			},
+			{
+				Config: dataSourceTokensConfig,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_policy", "accessors.#", "1"),
+					resource.TestCheckResourceAttrPair("data.consulacl_tokens.by_policy", "accessors.0", "consulacl_token14.first", consulacl.FieldAccessor),
+					resource.TestCheckResourceAttrPair("data.consulacl_tokens.by_policy", "tokens.0.secret", "consulacl_token14.first", consulacl.FieldSecret),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_policy", "tokens.0.policies.#", "1"),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_policy", "tokens.0.policies.0", "test-tokens-filter"),
+
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_description", "accessors.#", "2"),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_description", "tokens.0.description", "Test Tokens pipeline-first"),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_description", "tokens.0.secret", ""),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.by_description", "tokens.1.description", "Test Tokens pipeline-second"),
+					resource.TestCheckResourceAttrSet("data.consulacl_tokens.by_description", "tokens.1.expiration_time"),
+
+					resource.TestCheckResourceAttr("data.consulacl_tokens.expiring", "accessors.#", "1"),
+					resource.TestCheckResourceAttrPair("data.consulacl_tokens.expiring", "accessors.0", "consulacl_token14.second", consulacl.FieldAccessor),
+
+					resource.TestCheckResourceAttr("data.consulacl_tokens.global", "accessors.#", "2"),
+					resource.TestCheckResourceAttr("data.consulacl_tokens.legacy", "accessors.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestDataSourceTokensInvalidRegex(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      `data "consulacl_tokens" "test" { description_regex = "(" }`,
+				ExpectError: regexp.MustCompile("description_regex"),
+			},
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Moving an object to another datacenter replaces it, e.g. local tokens only exist in the datacenter they're created in
+func datacenterSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		ForceNew:    true,
+		Description: "Datacenter to manage the object in, defaults to the provider's datacenter",
+	}
+}
+
+func dataSourceDatacenterSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Computed:    true,
+		Description: "Datacenter to query, defaults to the provider's datacenter",
+	}
+}
+
+// Returns a client for the datacenter of the resource and records the datacenter in the state so that later reads
+// query the same datacenter even if the provider's default changes
+func getClient(d *schema.ResourceData, meta interface{}) (*consul.Client, error) {
+	dc := d.Get(FieldDatacenter).(string)
+	if dc == "" {
+		var err error
+		if dc, err = meta.(*Meta).defaultDatacenter(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := d.Set(FieldDatacenter, dc); err != nil {
+		return nil, fmt.Errorf("error while setting %q: %s", FieldDatacenter, err)
+	}
+
+	return meta.(*Meta).datacenterClient(dc)
+}
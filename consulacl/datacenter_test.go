@@ -0,0 +1,139 @@
+package consulacl_test
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const datacenterLocalAccessor = "3b9d1f7e-5a2c-4e8b-b6d4-7c0e2a9f1d3b"
+const datacenterPrimaryAccessor = "e1a7c3d9-6f2b-4d8e-a0c5-9b3f7e1d5a2c"
+
+const datacenterResources = `
+resource "consulacl_policy" "test" {
+	name = "test-datacenter"
+	rules = "operator = \"read\""
+}
+
+resource "consulacl_token14" "local" {
+	accessor = "3b9d1f7e-5a2c-4e8b-b6d4-7c0e2a9f1d3b"
+	description = "Test Local Token"
+	policies = ["${consulacl_policy.test.name}"]
+	local = true
+}
+
+resource "consulacl_token14" "primary" {
+	accessor = "e1a7c3d9-6f2b-4d8e-a0c5-9b3f7e1d5a2c"
+	description = "Test Primary Token"
+	local = true
+	datacenter = "dc1"
+}
+
+data "consulacl_token" "local" {
+	accessor = "${consulacl_token14.local.id}"
+	datacenter = "dc2"
+}
+`
+
+const datacenterConfigProviderDefault = `
+provider "consulacl" {
+	datacenter = "dc2"
+}
+` + datacenterResources
+
+func TestIntegrationDatacenter(t *testing.T) {
+	check := resource.ComposeTestCheckFunc(
+		resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldDatacenter, "dc2"),
+		resource.TestCheckResourceAttr("consulacl_token14.local", consulacl.FieldDatacenter, "dc2"),
+		resource.TestCheckResourceAttr("consulacl_token14.primary", consulacl.FieldDatacenter, "dc1"),
+		resource.TestCheckResourceAttr("data.consulacl_token.local", consulacl.FieldDescription, "Test Local Token"),
+		testDatacenterToken(datacenterLocalAccessor, fakeconsul.SecondaryDatacenter, true),
+		testDatacenterToken(datacenterLocalAccessor, fakeconsul.PrimaryDatacenter, false),
+		testDatacenterToken(datacenterPrimaryAccessor, fakeconsul.PrimaryDatacenter, true),
+		testDatacenterToken(datacenterPrimaryAccessor, fakeconsul.SecondaryDatacenter, false),
+	)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testSecondaryDatacenterPreCheck(t) },
+		CheckDestroy: resource.ComposeTestCheckFunc(
+			testDatacenterToken(datacenterLocalAccessor, fakeconsul.SecondaryDatacenter, false),
+			testDatacenterToken(datacenterPrimaryAccessor, fakeconsul.PrimaryDatacenter, false),
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: datacenterConfigProviderDefault,
+				Check:  check,
+			},
+			// objects keep being read from the datacenters they were created in after the provider's default changes
+			{
+				Config: datacenterResources,
+				Check:  check,
+			},
+		},
+	})
+}
+
+func testDatacenterToken(accessor, dc string, expected bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tokens, _, err := testClient.ACL().TokenList(&consul.QueryOptions{Datacenter: dc})
+		if err != nil {
+			return fmt.Errorf("error listing ACL tokens in datacenter %q: %s", dc, err)
+		}
+
+		found := false
+		for _, entry := range tokens {
+			if entry.AccessorID == accessor {
+				found = true
+			}
+		}
+
+		if found != expected {
+			return fmt.Errorf("expected ACL token %q to exist in datacenter %q: %t, got: %t", accessor, dc, expected, found)
+		}
+		return nil
+	}
+}
+
+const datacenterConfigAgentDefault = `
+resource "consulacl_policy" "agent" {
+	name = "test-datacenter-agent"
+	rules = "operator = \"read\""
+}
+`
+
+const datacenterConfigAgentExplicit = `
+resource "consulacl_policy" "agent" {
+	name = "test-datacenter-agent"
+	rules = "operator = \"read\""
+	datacenter = "dc1"
+}
+`
+
+// Objects are pinned to the datacenter of the agent when neither they nor the provider configure one
+func TestIntegrationDatacenterAgentDefault(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: datacenterConfigAgentDefault,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_policy.agent", consulacl.FieldDatacenter, fakeconsul.PrimaryDatacenter),
+				),
+			},
+			// setting the same datacenter explicitly doesn't replace the object
+			{
+				Config:   datacenterConfigAgentExplicit,
+				PlanOnly: true,
+			},
+		},
+	})
+}
@@ -0,0 +1,150 @@
+package consulacl
+
+import (
+	"errors"
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type aclErrorKind int
+
+const (
+	aclErrorUnexpected aclErrorKind = iota
+	aclErrorNotFound
+	aclErrorPermissionDenied
+	aclErrorDisabled
+	aclErrorLegacyMode
+	aclErrorUnavailable
+	aclErrorUnsupported
+)
+
+// Consul API client reports non-200 responses only as formatted strings
+var unexpectedResponseRegexp = regexp.MustCompile(`(?s)^Unexpected response code: (\d+) \((.*)\)$`)
+
+const unavailableHint = "Consul is unavailable, the operation can be retried"
+
+// Consul answers with 500 both for invalid requests and for cluster issues so only the latter are told apart by messages
+var unavailableMessages = []string{
+	"No cluster leader",
+	"No path to datacenter",
+	"i/o timeout",
+	"connection refused",
+	"connection reset",
+	"rpc error making call: EOF",
+	"rpc error making call: unexpected EOF",
+}
+
+// Consul answers with an empty 404 to requests to endpoints it doesn't have, only 404s that tell what's missing are
+// about missing objects. Reads of objects that report them with an empty 404 never get here as the client returns nil.
+var notFoundRegexp = regexp.MustCompile(`(?i)not found`)
+
+const unsupportedHint = "the endpoint is not supported by this version of Consul, tokens and policies require " +
+	"Consul 1.4.0+ while roles, auth methods and binding rules require Consul 1.5.0+"
+
+// aclError is an error returned by Consul ACL API classified so that resources can react to it properly,
+// e.g. only objects that are really gone should be removed from the state
+type aclError struct {
+	kind  aclErrorKind
+	hint  string
+	cause error
+}
+
+func (e *aclError) Error() string {
+	if e.hint == "" {
+		return e.cause.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.hint, e.cause)
+}
+
+func (e *aclError) Unwrap() error {
+	return e.cause
+}
+
+// Maps an error returned by Consul ACL API to an aclError, the client is used to tell apart a missing object from
+// a missing token used by the provider as Consul responds with the same "ACL not found" to both
+func classifyACLError(client *consul.Client, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var classified *aclError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	// responses cut short are only noticed once their bodies are decoded
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &aclError{kind: aclErrorUnavailable, hint: unavailableHint, cause: err}
+	}
+
+	match := unexpectedResponseRegexp.FindStringSubmatch(err.Error())
+	if match == nil {
+		return &aclError{kind: aclErrorUnexpected, cause: err}
+	}
+	status, _ := strconv.Atoi(match[1])
+	message := match[2]
+
+	switch {
+	case status == 404 && notFoundRegexp.MatchString(message):
+		return &aclError{kind: aclErrorNotFound, cause: err}
+	case status == 404:
+		return &aclError{kind: aclErrorUnsupported, hint: unsupportedHint, cause: err}
+	case status == 403 && strings.Contains(message, "ACL not found"):
+		if _, _, selfErr := client.ACL().TokenReadSelf(nil); selfErr != nil {
+			if strings.Contains(selfErr.Error(), "ACL not found") {
+				return &aclError{
+					kind:  aclErrorPermissionDenied,
+					hint:  "the ACL token used by the provider doesn't exist in Consul",
+					cause: err,
+				}
+			}
+			// without knowing whether the provider's token is valid the object cannot be considered missing
+			return classifyACLError(client, selfErr)
+		}
+		return &aclError{kind: aclErrorNotFound, cause: err}
+	case status == 403:
+		return &aclError{
+			kind:  aclErrorPermissionDenied,
+			hint:  `permission denied, the ACL token used by the provider needs "acl = \"write\"" to manage ACLs`,
+			cause: err,
+		}
+	case status == 401 || strings.Contains(message, "ACL support disabled"):
+		return &aclError{
+			kind:  aclErrorDisabled,
+			hint:  "ACLs are disabled in Consul, they have to be enabled in the configuration of Consul servers",
+			cause: err,
+		}
+	case strings.Contains(strings.ToLower(message), "legacy mode"):
+		return &aclError{
+			kind:  aclErrorLegacyMode,
+			hint:  "the ACL system of Consul is in legacy mode, post-1.4 ACLs become available once all servers are upgraded",
+			cause: err,
+		}
+	case status == 429 || status == 502 || status == 503 || status == 504:
+		return &aclError{kind: aclErrorUnavailable, hint: unavailableHint, cause: err}
+	}
+
+	for _, unavailable := range unavailableMessages {
+		if strings.Contains(message, unavailable) {
+			return &aclError{kind: aclErrorUnavailable, hint: unavailableHint, cause: err}
+		}
+	}
+
+	return &aclError{kind: aclErrorUnexpected, cause: err}
+}
+
+func isACLErrorKind(err error, kind aclErrorKind) bool {
+	var classified *aclError
+	return errors.As(err, &classified) && classified.kind == kind
+}
+
+// Only errors that were classified as not found are reported as such
+func isNotFoundError(err error) bool {
+	return isACLErrorKind(err, aclErrorNotFound)
+}
@@ -0,0 +1,181 @@
+package consulacl_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
+	"github.com/hashicorp/terraform/config"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const errorsTokenAccessor = "4c0e7b1a-9d2f-4e6b-8a3c-1f5d7e9b2a4c"
+
+// Neither a missing token of the provider nor an unavailable Consul should make Terraform forget about a token,
+// only a token that is really gone should be removed from the state
+func TestIntegrationErrorsKeepState(t *testing.T) {
+	testResourcePreConfig(t)
+
+	_, _, err := testClient.ACL().TokenCreate(&consul.ACLToken{AccessorID: errorsTokenAccessor, Description: "Test Errors"}, nil)
+	if err != nil {
+		t.Fatalf("error creating a test ACL token: %s", err)
+	}
+	defer testClient.ACL().TokenDelete(errorsTokenAccessor, nil)
+
+	token14 := aclProvider.ResourcesMap["consulacl_token14"]
+
+	failures := []struct {
+		config   map[string]interface{}
+		expected *regexp.Regexp
+	}{
+		{
+			config:   map[string]interface{}{"token": "9e8d7c6b-5a4f-4e3d-8c2b-1a0f9e8d7c6b"},
+			expected: regexp.MustCompile(`the ACL token used by the provider doesn't exist in Consul`),
+		},
+		{
+			config:   map[string]interface{}{"address": "127.0.0.1:1", "retry_max_wait": "10ms"},
+			expected: regexp.MustCompile(`Consul is unavailable, the operation can be retried`),
+		},
+	}
+
+	for _, failure := range failures {
+		meta := testConfiguredProviderMeta(t, failure.config)
+
+		d := token14.Data(&terraform.InstanceState{ID: errorsTokenAccessor})
+		err = token14.Read(d, meta)
+		if err == nil || !failure.expected.MatchString(err.Error()) {
+			t.Fatalf("expected an error matching %q, got: %v", failure.expected, err)
+		}
+		if d.Id() != errorsTokenAccessor {
+			t.Fatalf("ACL token %q was removed from the state after an error: %s", errorsTokenAccessor, err)
+		}
+	}
+
+	if _, err = testClient.ACL().TokenDelete(errorsTokenAccessor, nil); err != nil {
+		t.Fatalf("error deleting the test ACL token: %s", err)
+	}
+
+	d := token14.Data(&terraform.InstanceState{ID: errorsTokenAccessor})
+	if err = token14.Read(d, aclProvider.Meta()); err != nil {
+		t.Fatalf("error reading a deleted ACL token: %s", err)
+	}
+	if d.Id() != "" {
+		t.Fatalf("deleted ACL token %q was kept in the state", errorsTokenAccessor)
+	}
+}
+
+// Returns meta of a separate provider instance so that the shared one stays intact
+func testConfiguredProviderMeta(t *testing.T, raw map[string]interface{}) interface{} {
+	rawConfig, err := config.NewRawConfig(raw)
+	if err != nil {
+		t.Fatalf("error initializing config for a provider instance: %s", err)
+	}
+
+	provider := consulacl.Provider().(*schema.Provider)
+	if err = provider.Configure(terraform.NewResourceConfig(rawConfig)); err != nil {
+		t.Fatalf("error configuring a provider instance: %s", err)
+	}
+
+	return provider.Meta()
+}
+
+// Responses that don't come from the ACL API of a Consul 1.4+ agent must not be mistaken for missing objects either
+func TestErrorsUnexpectedResponses(t *testing.T) {
+	token14 := aclProvider.ResourcesMap["consulacl_token14"]
+
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected *regexp.Regexp
+	}{
+		{
+			name:     "endpoint missing in older Consul",
+			status:   http.StatusNotFound,
+			expected: regexp.MustCompile(`the endpoint is not supported by this version of Consul`),
+		},
+		{
+			name:     "response cut short",
+			status:   http.StatusOK,
+			body:     `{"AccessorID": "4c0e7b1a`,
+			expected: regexp.MustCompile(`Consul is unavailable, the operation can be retried`),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			meta := testConfiguredProviderMeta(t, map[string]interface{}{
+				"address":            strings.TrimPrefix(server.URL, "http://"),
+				"scheme":             "http",
+				"datacenter":         "dc1",
+				"retry_max_attempts": 1,
+			})
+
+			d := token14.Data(&terraform.InstanceState{ID: errorsTokenAccessor})
+			err := token14.Read(d, meta)
+			if err == nil || !tc.expected.MatchString(err.Error()) {
+				t.Fatalf("expected an error matching %q, got: %v", tc.expected, err)
+			}
+			if d.Id() != errorsTokenAccessor {
+				t.Fatalf("ACL token %q was removed from the state after an error: %s", errorsTokenAccessor, err)
+			}
+		})
+	}
+}
+
+// Policies of Consul Enterprise may have rules that cannot be parsed, tokens linked to them still have to refresh
+func TestErrorsUnparsableEffectiveRules(t *testing.T) {
+	fake := fakeconsul.NewServer(testFakeMasterToken)
+	defer fake.Close()
+
+	meta := testConfiguredProviderMeta(t, map[string]interface{}{
+		"address": fake.Address(),
+		"scheme":  "http",
+		"token":   testFakeMasterToken,
+	})
+	client := meta.(*consulacl.Meta).Client
+
+	policy, _, err := client.ACL().PolicyCreate(&consul.ACLPolicy{Name: "test-errors-enterprise"}, nil)
+	if err != nil {
+		t.Fatalf("error creating a test ACL policy: %s", err)
+	}
+	fake.SetPolicyRules(policy.ID, `namespace "team" { key_prefix "" { policy = "read" } }`)
+
+	_, _, err = client.ACL().TokenCreate(&consul.ACLToken{
+		AccessorID: errorsTokenAccessor,
+		Policies:   []*consul.ACLTokenPolicyLink{{ID: policy.ID}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("error creating a test ACL token: %s", err)
+	}
+
+	token14 := aclProvider.ResourcesMap["consulacl_token14"]
+	d := token14.Data(&terraform.InstanceState{ID: errorsTokenAccessor})
+	if err = token14.Read(d, meta); err != nil {
+		t.Fatalf("error reading an ACL token linked to a policy with unparsable rules: %s", err)
+	}
+	if rules := d.Get("effective_rules").(string); rules != "" {
+		t.Fatalf("expected effective rules to be left empty, got: %q", rules)
+	}
+
+	token := aclProvider.DataSourcesMap["consulacl_token"]
+	d = schema.TestResourceDataRaw(t, token.Schema, map[string]interface{}{"accessor": errorsTokenAccessor})
+	if err = token.Read(d, meta); err != nil {
+		t.Fatalf("error reading an ACL token linked to a policy with unparsable rules: %s", err)
+	}
+	if rules := d.Get("effective_rules").(string); rules != "" {
+		t.Fatalf("expected effective rules to be left empty, got: %q", rules)
+	}
+}
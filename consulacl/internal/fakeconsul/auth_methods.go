@@ -0,0 +1,289 @@
+package fakeconsul
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+)
+
+var authMethodNameRegexp = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,128}$`)
+
+var authMethodTypes = []string{"kubernetes", "jwt", "oidc", "testing"}
+
+func validateAuthMethod(method *consul.ACLAuthMethod) error {
+	if method.Name == "" {
+		return fmt.Errorf("Invalid Auth Method: no Name is set")
+	}
+	if !authMethodNameRegexp.MatchString(method.Name) {
+		return fmt.Errorf("Invalid Auth Method: invalid Name. Only alphanumeric characters, '-' and '_' are allowed")
+	}
+
+	known := false
+	for _, methodType := range authMethodTypes {
+		known = known || method.Type == methodType
+	}
+	if !known {
+		return fmt.Errorf("Invalid Auth Method: Type should be one of: %s", strings.Join(authMethodTypes, ", "))
+	}
+
+	if method.Type == "kubernetes" {
+		config, err := consul.ParseKubernetesAuthMethodConfig(method.Config)
+		if err != nil {
+			return fmt.Errorf("Invalid Auth Method: %v", err)
+		}
+		if config.Host == "" {
+			return fmt.Errorf("Invalid Auth Method: Config.Host is required")
+		}
+		if block, _ := pem.Decode([]byte(config.CACert)); block == nil {
+			return fmt.Errorf("Invalid Auth Method: Config.CACert is not a valid PEM encoded certificate")
+		}
+		if config.ServiceAccountJWT == "" {
+			return fmt.Errorf("Invalid Auth Method: Config.ServiceAccountJWT is required")
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) putAuthMethod(method *consul.ACLAuthMethod) {
+	method.ModifyIndex = s.nextIndex()
+	if method.CreateIndex == 0 {
+		method.CreateIndex = method.ModifyIndex
+	}
+	s.authMethods[method.Name] = method
+}
+
+func (s *Server) authMethodCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var method consul.ACLAuthMethod
+	if err := decodeBody(r, &method); err != nil {
+		return nil, err
+	}
+
+	if err := validateAuthMethod(&method); err != nil {
+		return nil, err
+	}
+	if _, ok := s.authMethods[method.Name]; ok {
+		return nil, fmt.Errorf("Invalid Auth Method: An Auth Method with Name %q already exists", method.Name)
+	}
+
+	method.CreateIndex = 0
+	s.putAuthMethod(&method)
+	return &method, nil
+}
+
+func (s *Server) authMethodUpdate(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var method consul.ACLAuthMethod
+	if err := decodeBody(r, &method); err != nil {
+		return nil, err
+	}
+
+	name := args[0]
+	if method.Name != "" && method.Name != name {
+		return nil, badRequest("Auth Method update failed: Name doesn't match the URL parameter")
+	}
+	method.Name = name
+
+	existing, ok := s.authMethods[name]
+	if !ok {
+		return nil, fmt.Errorf("Cannot find auth method %s", name)
+	}
+	if method.Type != existing.Type {
+		return nil, fmt.Errorf("Invalid Auth Method: cannot change the Type of an existing auth method")
+	}
+
+	if err := validateAuthMethod(&method); err != nil {
+		return nil, err
+	}
+
+	method.CreateIndex = existing.CreateIndex
+	s.putAuthMethod(&method)
+	return &method, nil
+}
+
+func (s *Server) authMethodRead(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	method, ok := s.authMethods[args[0]]
+	if !ok {
+		return nil, errNotFound
+	}
+	return method, nil
+}
+
+// Binding rules of an auth method are deleted together with it
+func (s *Server) authMethodDelete(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	delete(s.authMethods, args[0])
+	for id, rule := range s.bindingRules {
+		if rule.AuthMethod == args[0] {
+			delete(s.bindingRules, id)
+		}
+	}
+	s.nextIndex()
+	return true, nil
+}
+
+func (s *Server) authMethodList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	result := make([]*consul.ACLAuthMethodListEntry, 0, len(s.authMethods))
+	for _, method := range s.authMethods {
+		result = append(result, &consul.ACLAuthMethodListEntry{
+			Name:        method.Name,
+			Type:        method.Type,
+			Description: method.Description,
+			CreateIndex: method.CreateIndex,
+			ModifyIndex: method.ModifyIndex,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (s *Server) validateBindingRule(rule *consul.ACLBindingRule) error {
+	if _, ok := s.authMethods[rule.AuthMethod]; !ok {
+		return fmt.Errorf("Invalid Binding Rule: unknown AuthMethod %q", rule.AuthMethod)
+	}
+
+	switch rule.BindType {
+	case consul.BindingRuleBindTypeService, consul.BindingRuleBindTypeRole:
+	default:
+		return fmt.Errorf("Invalid Binding Rule: unknown BindType %q", rule.BindType)
+	}
+
+	if rule.BindName == "" {
+		return fmt.Errorf("Invalid Binding Rule: no BindName is set")
+	}
+
+	return nil
+}
+
+func (s *Server) putBindingRule(rule *consul.ACLBindingRule) {
+	rule.ModifyIndex = s.nextIndex()
+	if rule.CreateIndex == 0 {
+		rule.CreateIndex = rule.ModifyIndex
+	}
+	s.bindingRules[rule.ID] = rule
+}
+
+func (s *Server) bindingRuleCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var rule consul.ACLBindingRule
+	if err := decodeBody(r, &rule); err != nil {
+		return nil, err
+	}
+
+	if rule.ID != "" {
+		return nil, fmt.Errorf("Invalid Binding Rule: ID must not be set on creation")
+	}
+	if err := s.validateBindingRule(&rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = generateUUID()
+	rule.CreateIndex = 0
+	s.putBindingRule(&rule)
+	return &rule, nil
+}
+
+func (s *Server) bindingRuleUpdate(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var rule consul.ACLBindingRule
+	if err := decodeBody(r, &rule); err != nil {
+		return nil, err
+	}
+
+	id := args[0]
+	if rule.ID != "" && rule.ID != id {
+		return nil, badRequest("Binding Rule update failed: ID doesn't match the URL parameter")
+	}
+	rule.ID = id
+
+	existing, ok := s.bindingRules[id]
+	if !ok {
+		return nil, fmt.Errorf("Cannot find binding rule %s", id)
+	}
+	if rule.AuthMethod != existing.AuthMethod {
+		return nil, fmt.Errorf("Invalid Binding Rule: cannot change the AuthMethod of an existing binding rule")
+	}
+	if err := s.validateBindingRule(&rule); err != nil {
+		return nil, err
+	}
+
+	rule.CreateIndex = existing.CreateIndex
+	s.putBindingRule(&rule)
+	return &rule, nil
+}
+
+func (s *Server) bindingRuleRead(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	rule, ok := s.bindingRules[args[0]]
+	if !ok {
+		return nil, errNotFound
+	}
+	return rule, nil
+}
+
+func (s *Server) bindingRuleDelete(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	delete(s.bindingRules, args[0])
+	s.nextIndex()
+	return true, nil
+}
+
+func (s *Server) bindingRuleList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	authMethod := r.URL.Query().Get("authmethod")
+	if authMethod != "" {
+		if _, ok := s.authMethods[authMethod]; !ok {
+			return nil, fmt.Errorf("Invalid Auth Method: unknown AuthMethod %q", authMethod)
+		}
+	}
+
+	result := make([]*consul.ACLBindingRule, 0, len(s.bindingRules))
+	for _, rule := range s.bindingRules {
+		if authMethod == "" || rule.AuthMethod == authMethod {
+			result = append(result, rule)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package fakeconsul
+
+import (
+	"net/http"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+)
+
+// Resolves the token a request is made with and makes sure it grants the given access to the ACL system.
+// An empty access only requires the token to exist, e.g. to read the token itself.
+func (s *Server) authorize(r *http.Request, access string) (*token, error) {
+	secret := secretOf(r)
+	if secret == "" {
+		secret = AnonymousTokenSecret
+	}
+
+	t := s.tokenBySecret(secret)
+	if t == nil {
+		return nil, errACLNotFound
+	}
+
+	if access != "" && !s.allows(r, t, access) {
+		return nil, errPermissionDenied
+	}
+
+	return t, nil
+}
+
+func (s *Server) allows(r *http.Request, t *token, access string) bool {
+	if t.Type == legacyTypeManagement {
+		return true
+	}
+
+	decision, err := rules.NewAuthorizer(false, s.tokenRules(t, datacenterOf(r))...).Authorize("acl", "", access)
+	return err == nil && decision.Allowed
+}
+
+// Stored rules are validated upon writes so they always parse, service identities only apply in their datacenters
+func (s *Server) tokenRules(t *token, dc string) []rules.Rules {
+	var result []rules.Rules
+
+	for _, link := range t.Policies {
+		result = append(result, s.policyRules(link.ID)...)
+	}
+
+	for _, link := range t.Roles {
+		role, ok := s.roles[link.ID]
+		if !ok {
+			continue
+		}
+		for _, policyLink := range role.Policies {
+			result = append(result, s.policyRules(policyLink.ID)...)
+		}
+		for _, identity := range role.ServiceIdentities {
+			result = append(result, rules.ServiceIdentity(identity.ServiceName, identity.Datacenters, dc))
+		}
+	}
+
+	for _, identity := range t.ServiceIdentities {
+		result = append(result, rules.ServiceIdentity(identity.ServiceName, identity.Datacenters, dc))
+	}
+
+	if t.Rules != "" {
+		// Legacy rules are always prefix matches
+		parsed, _ := rules.Parse(t.Rules)
+		for i := range parsed {
+			parsed[i].Prefix = rules.IsPrefixed(parsed[i].Resource)
+		}
+		result = append(result, parsed)
+	}
+
+	return result
+}
+
+func (s *Server) policyRules(id string) []rules.Rules {
+	policy, ok := s.policies[id]
+	if !ok {
+		return nil
+	}
+	parsed, _ := rules.Parse(policy.Rules)
+	return []rules.Rules{parsed}
+}
@@ -0,0 +1,159 @@
+package fakeconsul
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+)
+
+// Legacy tokens are identified by their secrets, a management one keeps its rules and is linked to the
+// global-management policy the same way Consul's legacy token upgrade does
+func (s *Server) legacyApply(entry *consul.ACLEntry) (string, error) {
+	switch entry.Type {
+	case "":
+		entry.Type = legacyTypeClient
+	case legacyTypeClient, legacyTypeManagement:
+	default:
+		return "", fmt.Errorf("Invalid ACL Type")
+	}
+
+	if entry.Rules != "" {
+		if _, err := rules.Parse(entry.Rules); err != nil {
+			return "", fmt.Errorf("ACL rule compilation failed: %v", err)
+		}
+	}
+
+	if entry.ID == "" {
+		entry.ID = generateUUID()
+	}
+
+	t := s.tokenBySecret(entry.ID)
+	if t == nil {
+		t = &token{ACLToken: consul.ACLToken{
+			AccessorID: generateUUID(),
+			SecretID:   entry.ID,
+			CreateTime: time.Now(),
+		}}
+	} else {
+		updated := *t
+		t = &updated
+	}
+
+	t.Type = entry.Type
+	t.Description = entry.Name
+	t.Rules = entry.Rules
+	t.Policies = nil
+	t.Roles = nil
+	t.ServiceIdentities = nil
+	if t.Type == legacyTypeManagement {
+		t.Policies = []*consul.ACLTokenPolicyLink{{ID: GlobalManagementPolicyID}}
+	}
+
+	s.putToken(t)
+	return t.SecretID, nil
+}
+
+func (s *Server) legacyEntry(t *token) *consul.ACLEntry {
+	entryType := t.Type
+	if entryType == "" {
+		entryType = legacyTypeClient
+		if hasTokenPolicy(t, GlobalManagementPolicyID) {
+			entryType = legacyTypeManagement
+		}
+	}
+
+	return &consul.ACLEntry{
+		CreateIndex: t.CreateIndex,
+		ModifyIndex: t.ModifyIndex,
+		ID:          t.SecretID,
+		Name:        t.Description,
+		Type:        entryType,
+		Rules:       t.Rules,
+	}
+}
+
+func (s *Server) legacyCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var entry consul.ACLEntry
+	if err := decodeBody(r, &entry); err != nil {
+		return nil, err
+	}
+
+	id, err := s.legacyApply(&entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"ID": id}, nil
+}
+
+func (s *Server) legacyUpdate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var entry consul.ACLEntry
+	if err := decodeBody(r, &entry); err != nil {
+		return nil, err
+	}
+
+	if entry.ID == "" {
+		return nil, badRequest("ACL ID must be set")
+	}
+
+	id, err := s.legacyApply(&entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"ID": id}, nil
+}
+
+func (s *Server) legacyDestroy(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	if t := s.tokenBySecret(args[0]); t != nil {
+		if t.AccessorID == AnonymousTokenAccessor {
+			return nil, fmt.Errorf("Delete operation not permitted on the anonymous token")
+		}
+		delete(s.tokens, t.AccessorID)
+		s.nextIndex()
+	}
+
+	return true, nil
+}
+
+func (s *Server) legacyInfo(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	result := make([]*consul.ACLEntry, 0, 1)
+	if t := s.tokenBySecret(args[0]); t != nil {
+		result = append(result, s.legacyEntry(t))
+	}
+	return result, nil
+}
+
+func (s *Server) legacyList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	result := make([]*consul.ACLEntry, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		result = append(result, s.legacyEntry(t))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
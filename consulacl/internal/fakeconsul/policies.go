@@ -0,0 +1,226 @@
+package fakeconsul
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+)
+
+var policyNameRegexp = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,128}$`)
+
+func (s *Server) putPolicy(policy *consul.ACLPolicy) {
+	policy.Hash = hashOf(policy.Name, policy.Description, policy.Rules, policy.Datacenters)
+	policy.ModifyIndex = s.nextIndex()
+	if policy.CreateIndex == 0 {
+		policy.CreateIndex = policy.ModifyIndex
+	}
+	s.recordChange(policy.ID, s.policies[policy.ID])
+	s.policies[policy.ID] = policy
+}
+
+// SetPolicyRules replaces rules of a policy without validating them, e.g. to stand in for rules of Consul Enterprise.
+func (s *Server) SetPolicyRules(id, rules string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policy := *s.policies[id]
+	policy.Rules = rules
+	s.putPolicy(&policy)
+}
+
+func (s *Server) policyByName(name string) *consul.ACLPolicy {
+	for _, policy := range s.policies {
+		if policy.Name == name {
+			return policy
+		}
+	}
+	return nil
+}
+
+// Returns IDs of the linked policies, links may refer to policies either by IDs or by names
+func (s *Server) resolvePolicyLinks(links []*consul.ACLTokenPolicyLink) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, link := range links {
+		id := link.ID
+		if id == "" {
+			policy := s.policyByName(link.Name)
+			if policy == nil {
+				return nil, fmt.Errorf("No such ACL policy with name %q", link.Name)
+			}
+			id = policy.ID
+		} else if _, ok := s.policies[id]; !ok {
+			return nil, fmt.Errorf("No such ACL policy with ID %q", id)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Server) policyLinksView(links []*consul.ACLTokenPolicyLink) []*consul.ACLTokenPolicyLink {
+	var result []*consul.ACLTokenPolicyLink
+	for _, link := range links {
+		if policy, ok := s.policies[link.ID]; ok {
+			result = append(result, &consul.ACLTokenPolicyLink{ID: policy.ID, Name: policy.Name})
+		}
+	}
+	return result
+}
+
+func (s *Server) validatePolicy(policy *consul.ACLPolicy) error {
+	if !policyNameRegexp.MatchString(policy.Name) {
+		return fmt.Errorf("Invalid Policy: invalid Name. Only alphanumeric characters, '-' and '_' are allowed")
+	}
+
+	if existing := s.policyByName(policy.Name); existing != nil && existing.ID != policy.ID {
+		return fmt.Errorf("Invalid Policy: A Policy with Name %q already exists", policy.Name)
+	}
+
+	if _, err := rules.Parse(policy.Rules); err != nil {
+		return fmt.Errorf("Invalid Policy: failed to parse rules: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Server) policyCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLPolicy
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	if request.ID != "" {
+		return nil, fmt.Errorf("Invalid Policy: ID must not be set on creation")
+	}
+	request.ID = generateUUID()
+
+	if err := s.validatePolicy(&request); err != nil {
+		return nil, err
+	}
+
+	policy := &consul.ACLPolicy{
+		ID:          request.ID,
+		Name:        request.Name,
+		Description: request.Description,
+		Rules:       request.Rules,
+		Datacenters: request.Datacenters,
+	}
+	s.putPolicy(policy)
+
+	return policy, nil
+}
+
+func (s *Server) policyUpdate(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLPolicy
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	id := args[0]
+	if request.ID != "" && request.ID != id {
+		return nil, badRequest("Policy update failed: ID doesn't match the URL parameter")
+	}
+	request.ID = id
+
+	existing, ok := s.policies[id]
+	if !ok {
+		return nil, fmt.Errorf("Invalid Policy: A Policy with ID %q does not exist", id)
+	}
+
+	if id == GlobalManagementPolicyID {
+		if request.Name != existing.Name {
+			return nil, fmt.Errorf("Changing the Name for the builtin global-management policy is not permitted")
+		}
+		if request.Rules != existing.Rules {
+			return nil, fmt.Errorf("Changing the Rules for the builtin global-management policy is not permitted")
+		}
+	}
+
+	if err := s.validatePolicy(&request); err != nil {
+		return nil, err
+	}
+
+	policy := &consul.ACLPolicy{
+		ID:          id,
+		Name:        request.Name,
+		Description: request.Description,
+		Rules:       request.Rules,
+		Datacenters: request.Datacenters,
+		CreateIndex: existing.CreateIndex,
+	}
+	s.putPolicy(policy)
+
+	return policy, nil
+}
+
+func (s *Server) policyRead(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	policy, ok := s.policies[args[0]]
+	if !ok {
+		return nil, errACLNotFound
+	}
+
+	replicated, _ := s.replicaOf(r, policy.ID, policy).(*consul.ACLPolicy)
+	if replicated == nil {
+		return nil, errACLNotFound
+	}
+	return replicated, nil
+}
+
+// Links to a deleted policy are left dangling and disappear once tokens and roles are read
+func (s *Server) policyDelete(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	if args[0] == GlobalManagementPolicyID {
+		return nil, fmt.Errorf("Delete operation not permitted on the builtin global-management policy")
+	}
+
+	delete(s.policies, args[0])
+	s.nextIndex()
+	return true, nil
+}
+
+func (s *Server) policyList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	result := make([]*consul.ACLPolicyListEntry, 0, len(s.policies))
+	for _, policy := range s.policies {
+		result = append(result, &consul.ACLPolicyListEntry{
+			ID:          policy.ID,
+			Name:        policy.Name,
+			Description: policy.Description,
+			Datacenters: policy.Datacenters,
+			Hash:        policy.Hash,
+			CreateIndex: policy.CreateIndex,
+			ModifyIndex: policy.ModifyIndex,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package fakeconsul
+
+import (
+	"net/http"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// The fake serves a primary datacenter where all writes go to and a secondary one that replicates ACLs from it.
+const (
+	PrimaryDatacenter   = "dc1"
+	SecondaryDatacenter = "dc2"
+)
+
+var errNoPathToDatacenter = &httpError{http.StatusInternalServerError, "No path to datacenter"}
+
+// An object as it's seen in the secondary datacenter until the replication catches up with its latest change
+type replica struct {
+	visibleAt time.Time
+	previous  interface{} // a nil pointer if the object didn't exist before the change
+}
+
+// SetReplicationLag delays visibility of changes to tokens, policies and roles in the secondary datacenter.
+func (s *Server) SetReplicationLag(lag time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replicationLag = lag
+}
+
+func datacenterOf(r *http.Request) string {
+	if dc := r.URL.Query().Get("dc"); dc != "" {
+		return dc
+	}
+	return PrimaryDatacenter
+}
+
+func validateDatacenter(r *http.Request) error {
+	switch datacenterOf(r) {
+	case PrimaryDatacenter, SecondaryDatacenter:
+		return nil
+	default:
+		return errNoPathToDatacenter
+	}
+}
+
+// Records a change to an object so that the secondary datacenter keeps seeing its previous version for a while
+func (s *Server) recordChange(id string, previous interface{}) {
+	if s.replicationLag == 0 {
+		return
+	}
+
+	if pending, ok := s.replicas[id]; ok && time.Now().Before(pending.visibleAt) {
+		previous = pending.previous
+	}
+	s.replicas[id] = &replica{visibleAt: time.Now().Add(s.replicationLag), previous: previous}
+}
+
+// Returns the object as it's seen in the datacenter the request is for, nil if it's not there yet
+func (s *Server) replicaOf(r *http.Request, id string, current interface{}) interface{} {
+	if datacenterOf(r) != SecondaryDatacenter {
+		return current
+	}
+
+	pending, ok := s.replicas[id]
+	if !ok || !time.Now().Before(pending.visibleAt) {
+		return current
+	}
+	return pending.previous
+}
+
+// Mimics the status of ACL replication that is only enabled in secondary datacenters
+func (s *Server) replicationStatus(r *http.Request, _ []string) (interface{}, error) {
+	if datacenterOf(r) == PrimaryDatacenter {
+		return &consul.ACLReplicationStatus{SourceDatacenter: PrimaryDatacenter}, nil
+	}
+
+	return &consul.ACLReplicationStatus{
+		Enabled:              true,
+		Running:              true,
+		SourceDatacenter:     PrimaryDatacenter,
+		ReplicationType:      "tokens",
+		ReplicatedIndex:      s.index,
+		ReplicatedRoleIndex:  s.index,
+		ReplicatedTokenIndex: s.index,
+		LastSuccess:          time.Now(),
+	}, nil
+}
+
+// The agent always runs in the primary datacenter, only the datacenter is reported out of its configuration
+func (s *Server) agentSelf(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, ""); err != nil {
+		return nil, err
+	}
+
+	return map[string]map[string]interface{}{
+		"Config": {"Datacenter": PrimaryDatacenter},
+	}, nil
+}
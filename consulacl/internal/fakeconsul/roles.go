@@ -0,0 +1,250 @@
+package fakeconsul
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+)
+
+var roleNameRegexp = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,256}$`)
+
+func (s *Server) putRole(role *consul.ACLRole) {
+	role.Hash = hashOf(role.Name, role.Description, role.Policies, role.ServiceIdentities)
+	role.ModifyIndex = s.nextIndex()
+	if role.CreateIndex == 0 {
+		role.CreateIndex = role.ModifyIndex
+	}
+	s.recordChange(role.ID, s.roles[role.ID])
+	s.roles[role.ID] = role
+}
+
+func (s *Server) roleByName(name string) *consul.ACLRole {
+	for _, role := range s.roles {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+// Returns IDs of the linked roles, links may refer to roles either by IDs or by names
+func (s *Server) resolveRoleLinks(links []*consul.ACLTokenRoleLink) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+
+	for _, link := range links {
+		id := link.ID
+		if id == "" {
+			role := s.roleByName(link.Name)
+			if role == nil {
+				return nil, fmt.Errorf("No such ACL role with name %q", link.Name)
+			}
+			id = role.ID
+		} else if _, ok := s.roles[id]; !ok {
+			return nil, fmt.Errorf("No such ACL role with ID %q", id)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Server) roleLinksView(links []*consul.ACLTokenRoleLink) []*consul.ACLTokenRoleLink {
+	var result []*consul.ACLTokenRoleLink
+	for _, link := range links {
+		if role, ok := s.roles[link.ID]; ok {
+			result = append(result, &consul.ACLTokenRoleLink{ID: role.ID, Name: role.Name})
+		}
+	}
+	return result
+}
+
+func (s *Server) roleView(role *consul.ACLRole) *consul.ACLRole {
+	view := *role
+
+	var links []*consul.ACLTokenPolicyLink
+	for _, link := range role.Policies {
+		links = append(links, &consul.ACLTokenPolicyLink{ID: link.ID})
+	}
+
+	view.Policies = nil
+	for _, link := range s.policyLinksView(links) {
+		view.Policies = append(view.Policies, &consul.ACLRolePolicyLink{ID: link.ID, Name: link.Name})
+	}
+
+	return &view
+}
+
+func (s *Server) buildRole(request *consul.ACLRole) (*consul.ACLRole, error) {
+	if !roleNameRegexp.MatchString(request.Name) {
+		return nil, fmt.Errorf("Invalid Role: invalid Name. Only alphanumeric characters, '-' and '_' are allowed")
+	}
+
+	if existing := s.roleByName(request.Name); existing != nil && existing.ID != request.ID {
+		return nil, fmt.Errorf("Invalid Role: A Role with Name %q already exists", request.Name)
+	}
+
+	for _, identity := range request.ServiceIdentities {
+		if err := validateServiceIdentity(identity); err != nil {
+			return nil, err
+		}
+	}
+
+	var links []*consul.ACLTokenPolicyLink
+	for _, link := range request.Policies {
+		links = append(links, &consul.ACLTokenPolicyLink{ID: link.ID, Name: link.Name})
+	}
+	policies, err := s.resolvePolicyLinks(links)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &consul.ACLRole{
+		ID:                request.ID,
+		Name:              request.Name,
+		Description:       request.Description,
+		ServiceIdentities: request.ServiceIdentities,
+	}
+	for _, id := range policies {
+		role.Policies = append(role.Policies, &consul.ACLRolePolicyLink{ID: id})
+	}
+
+	return role, nil
+}
+
+func (s *Server) roleCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLRole
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	if request.ID != "" {
+		return nil, fmt.Errorf("Invalid Role: ID must not be set on creation")
+	}
+	request.ID = generateUUID()
+
+	role, err := s.buildRole(&request)
+	if err != nil {
+		return nil, err
+	}
+	s.putRole(role)
+
+	return s.roleView(role), nil
+}
+
+func (s *Server) roleUpdate(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLRole
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	id := args[0]
+	if request.ID != "" && request.ID != id {
+		return nil, badRequest("Role update failed: ID doesn't match the URL parameter")
+	}
+	request.ID = id
+
+	existing, ok := s.roles[id]
+	if !ok {
+		return nil, fmt.Errorf("Cannot find role %s", id)
+	}
+
+	role, err := s.buildRole(&request)
+	if err != nil {
+		return nil, err
+	}
+	role.CreateIndex = existing.CreateIndex
+	s.putRole(role)
+
+	return s.roleView(role), nil
+}
+
+func (s *Server) roleRead(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	role, ok := s.roles[args[0]]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	replicated, _ := s.replicaOf(r, role.ID, role).(*consul.ACLRole)
+	if replicated == nil {
+		return nil, errNotFound
+	}
+	return s.roleView(replicated), nil
+}
+
+func (s *Server) roleReadByName(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	role := s.roleByName(args[0])
+	if role == nil {
+		return nil, errNotFound
+	}
+
+	replicated, _ := s.replicaOf(r, role.ID, role).(*consul.ACLRole)
+	if replicated == nil {
+		return nil, errNotFound
+	}
+	return s.roleView(replicated), nil
+}
+
+// Links to a deleted role are left dangling and disappear once tokens are read
+func (s *Server) roleDelete(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	delete(s.roles, args[0])
+	s.nextIndex()
+	return true, nil
+}
+
+func (s *Server) roleList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	policy := r.URL.Query().Get("policy")
+
+	result := make([]*consul.ACLRole, 0, len(s.roles))
+	for _, role := range s.roles {
+		view := s.roleView(role)
+		if policy != "" && !hasRolePolicy(view, policy) {
+			continue
+		}
+		result = append(result, view)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func hasRolePolicy(role *consul.ACLRole, id string) bool {
+	for _, link := range role.Policies {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+// Package fakeconsul is an in-process fake of the Consul ACL HTTP API (the `/v1/acl/*` endpoints along with
+// `/v1/agent/self` to tell the datacenter of the agent) that is realistic enough to run the provider's tests without
+// a Consul agent: requests are authorized with ACL tokens, missing objects are reported with the same status codes and
+// messages, and invalid objects are rejected the way Consul does.
+package fakeconsul
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// Builtin objects have the same well-known IDs as in Consul.
+const (
+	GlobalManagementPolicyID   = "00000000-0000-0000-0000-000000000001"
+	GlobalManagementPolicyName = "global-management"
+	AnonymousTokenAccessor     = "00000000-0000-0000-0000-000000000002"
+	AnonymousTokenSecret       = "anonymous"
+)
+
+const globalManagementRules = `acl = "write"
+agent_prefix "" { policy = "write" }
+event_prefix "" { policy = "write" }
+key_prefix "" { policy = "write" }
+keyring = "write"
+node_prefix "" { policy = "write" }
+operator = "write"
+query_prefix "" { policy = "write" }
+service_prefix "" { policy = "write", intentions = "write" }
+session_prefix "" { policy = "write" }
+`
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Server is a fake Consul agent serving the ACL API over HTTP.
+type Server struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	index uint64
+
+	bootstrapped bool
+
+	tokens       map[string]*token // by accessor ID
+	policies     map[string]*consul.ACLPolicy
+	roles        map[string]*consul.ACLRole
+	authMethods  map[string]*consul.ACLAuthMethod
+	bindingRules map[string]*consul.ACLBindingRule
+
+	replicationLag time.Duration
+	replicas       map[string]*replica // by IDs of tokens, policies and roles
+}
+
+// NewServer starts a fake Consul agent. When a master token is given it's created the same way Consul does it for
+// `acl.tokens.master`, otherwise the ACL system has to be bootstrapped through the API.
+func NewServer(masterToken string) *Server {
+	s := &Server{
+		tokens:       make(map[string]*token),
+		policies:     make(map[string]*consul.ACLPolicy),
+		roles:        make(map[string]*consul.ACLRole),
+		authMethods:  make(map[string]*consul.ACLAuthMethod),
+		bindingRules: make(map[string]*consul.ACLBindingRule),
+		replicas:     make(map[string]*replica),
+	}
+
+	s.putPolicy(&consul.ACLPolicy{
+		ID:          GlobalManagementPolicyID,
+		Name:        GlobalManagementPolicyName,
+		Description: "Builtin Policy that grants unlimited access",
+		Rules:       globalManagementRules,
+	})
+
+	s.putToken(&token{ACLToken: consul.ACLToken{
+		AccessorID:  AnonymousTokenAccessor,
+		SecretID:    AnonymousTokenSecret,
+		Description: "Anonymous Token",
+		CreateTime:  time.Now(),
+	}})
+
+	if masterToken != "" {
+		s.bootstrapped = true
+		s.putToken(&token{ACLToken: consul.ACLToken{
+			AccessorID:  generateUUID(),
+			SecretID:    masterToken,
+			Description: "Master Token",
+			Policies:    []*consul.ACLTokenPolicyLink{{ID: GlobalManagementPolicyID}},
+			CreateTime:  time.Now(),
+		}})
+	}
+
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Address is the `host:port` the fake agent listens on.
+func (s *Server) Address() string {
+	return s.Listener.Addr().String()
+}
+
+// An error that is reported with the given status code, any other error is reported as an internal server error
+// which is what Consul does for errors coming from its RPC layer, e.g. validation ones.
+type httpError struct {
+	code    int
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+var (
+	errPermissionDenied = &httpError{http.StatusForbidden, "Permission denied"}
+	errACLNotFound      = &httpError{http.StatusForbidden, "ACL not found"}
+	errNotFound         = &httpError{http.StatusNotFound, ""}
+)
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+type handler func(r *http.Request, args []string) (interface{}, error)
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  handler
+}
+
+func (s *Server) routes() []route {
+	r := func(method, pattern string, handle handler) route {
+		return route{method, regexp.MustCompile("^/v1/acl/" + pattern + "$"), handle}
+	}
+
+	return []route{
+		{"GET", regexp.MustCompile("^/v1/agent/self$"), s.agentSelf},
+
+		r("PUT", "bootstrap", s.bootstrap),
+		r("GET", "replication", s.replicationStatus),
+
+		// Legacy API
+		r("PUT", "create", s.legacyCreate),
+		r("PUT", "update", s.legacyUpdate),
+		r("PUT", "destroy/([^/]+)", s.legacyDestroy),
+		r("GET", "info/([^/]+)", s.legacyInfo),
+		r("GET", "list", s.legacyList),
+
+		r("PUT", "token", s.tokenCreate),
+		r("GET", "token/self", s.tokenReadSelf),
+		r("GET", "token/([^/]+)", s.tokenRead),
+		r("PUT", "token/([^/]+)", s.tokenUpdate),
+		r("DELETE", "token/([^/]+)", s.tokenDelete),
+		r("GET", "tokens", s.tokenList),
+
+		r("PUT", "policy", s.policyCreate),
+		r("GET", "policy/([^/]+)", s.policyRead),
+		r("PUT", "policy/([^/]+)", s.policyUpdate),
+		r("DELETE", "policy/([^/]+)", s.policyDelete),
+		r("GET", "policies", s.policyList),
+
+		r("PUT", "role", s.roleCreate),
+		r("GET", "role/name/([^/]+)", s.roleReadByName),
+		r("GET", "role/([^/]+)", s.roleRead),
+		r("PUT", "role/([^/]+)", s.roleUpdate),
+		r("DELETE", "role/([^/]+)", s.roleDelete),
+		r("GET", "roles", s.roleList),
+
+		r("PUT", "auth-method", s.authMethodCreate),
+		r("GET", "auth-method/([^/]+)", s.authMethodRead),
+		r("PUT", "auth-method/([^/]+)", s.authMethodUpdate),
+		r("DELETE", "auth-method/([^/]+)", s.authMethodDelete),
+		r("GET", "auth-methods", s.authMethodList),
+
+		r("PUT", "binding-rule", s.bindingRuleCreate),
+		r("GET", "binding-rule/([^/]+)", s.bindingRuleRead),
+		r("PUT", "binding-rule/([^/]+)", s.bindingRuleUpdate),
+		r("DELETE", "binding-rule/([^/]+)", s.bindingRuleDelete),
+		r("GET", "binding-rules", s.bindingRuleList),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reapExpiredTokens()
+
+	if err := validateDatacenter(r); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	pathMatched := false
+	for _, route := range s.routes() {
+		match := route.pattern.FindStringSubmatch(r.URL.Path)
+		if match == nil {
+			continue
+		}
+		pathMatched = true
+		if route.method != r.Method {
+			continue
+		}
+
+		result, err := route.handle(r, match[1:])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(s.index, 10))
+		w.Header().Set("X-Consul-KnownLeader", "true")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	if pathMatched {
+		writeError(w, &httpError{http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method)})
+		return
+	}
+	writeError(w, &httpError{http.StatusNotFound, ""})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if e, ok := err.(*httpError); ok {
+		code = e.code
+	}
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(err.Error()))
+}
+
+func decodeBody(r *http.Request, out interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(out); err != nil {
+		return badRequest("Request decode failed: %v", err)
+	}
+	return nil
+}
+
+func (s *Server) nextIndex() uint64 {
+	s.index++
+	return s.index
+}
+
+func generateUUID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %s", err))
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16])
+}
+
+func hashOf(values ...interface{}) []byte {
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		panic(fmt.Sprintf("failed to hash %v: %s", values, err))
+	}
+	sum := sha256.Sum256(encoded)
+	return sum[:]
+}
+
+func secretOf(r *http.Request) string {
+	if secret := r.Header.Get("X-Consul-Token"); secret != "" {
+		return secret
+	}
+	if secret := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); secret != "" {
+		return secret
+	}
+	return r.URL.Query().Get("token")
+}
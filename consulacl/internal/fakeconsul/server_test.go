@@ -0,0 +1,199 @@
+package fakeconsul_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
+	consul "github.com/hashicorp/consul/api"
+)
+
+const masterToken = "6a1e3c5e-2b7d-4f3a-9c1e-8d4b2a6f0e7c"
+
+func newClient(t *testing.T, server *fakeconsul.Server, token string) *consul.Client {
+	client, err := consul.NewClient(&consul.Config{Address: server.Address(), Scheme: "http", Token: token})
+	if err != nil {
+		t.Fatalf("cannot create a client: %s", err)
+	}
+	return client
+}
+
+func TestAuthorization(t *testing.T) {
+	server := fakeconsul.NewServer(masterToken)
+	defer server.Close()
+
+	master := newClient(t, server, masterToken)
+
+	policy, _, err := master.ACL().PolicyCreate(&consul.ACLPolicy{Name: "read-only", Rules: `acl = "read"`}, nil)
+	if err != nil {
+		t.Fatalf("cannot create a policy: %s", err)
+	}
+
+	reader, _, err := master.ACL().TokenCreate(&consul.ACLToken{
+		Policies: []*consul.ACLTokenPolicyLink{{Name: policy.Name}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("cannot create a token: %s", err)
+	}
+
+	readOnly := newClient(t, server, reader.SecretID)
+
+	read, _, err := readOnly.ACL().TokenRead(reader.AccessorID, nil)
+	if err != nil {
+		t.Fatalf("cannot read a token: %s", err)
+	}
+	if read.SecretID == reader.SecretID {
+		t.Fatalf("secret is exposed to a token without write access")
+	}
+	if len(read.Policies) != 1 || read.Policies[0].ID != policy.ID {
+		t.Fatalf("expected the token to be linked to %q, got %#v", policy.ID, read.Policies)
+	}
+
+	_, _, err = readOnly.ACL().PolicyCreate(&consul.ACLPolicy{Name: "denied"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "403 (Permission denied)") {
+		t.Fatalf("expected permission to be denied, got %v", err)
+	}
+
+	_, _, err = newClient(t, server, "").ACL().PolicyList(nil)
+	if err == nil || !strings.Contains(err.Error(), "403 (Permission denied)") {
+		t.Fatalf("expected the anonymous token to be denied, got %v", err)
+	}
+
+	_, _, err = newClient(t, server, "unknown").ACL().PolicyList(nil)
+	if err == nil || !strings.Contains(err.Error(), "403 (ACL not found)") {
+		t.Fatalf("expected an unknown token to be rejected, got %v", err)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	server := fakeconsul.NewServer(masterToken)
+	defer server.Close()
+
+	acl := newClient(t, server, masterToken).ACL()
+	missing := "7f0c2d9e-4a1b-4c3d-8e5f-6a7b8c9d0e1f"
+
+	if _, _, err := acl.TokenRead(missing, nil); err == nil || !strings.Contains(err.Error(), "403 (ACL not found)") {
+		t.Fatalf("expected a missing token to be reported as not found, got %v", err)
+	}
+	if _, _, err := acl.PolicyRead(missing, nil); err == nil || !strings.Contains(err.Error(), "403 (ACL not found)") {
+		t.Fatalf("expected a missing policy to be reported as not found, got %v", err)
+	}
+	if role, _, err := acl.RoleRead(missing, nil); err != nil || role != nil {
+		t.Fatalf("expected a missing role to be nil, got %#v and %v", role, err)
+	}
+	if method, _, err := acl.AuthMethodRead("missing", nil); err != nil || method != nil {
+		t.Fatalf("expected a missing auth method to be nil, got %#v and %v", method, err)
+	}
+}
+
+func TestLegacyTokens(t *testing.T) {
+	server := fakeconsul.NewServer(masterToken)
+	defer server.Close()
+
+	acl := newClient(t, server, masterToken).ACL()
+
+	secret, _, err := acl.Create(&consul.ACLEntry{Name: "legacy", Type: "client", Rules: `key "" { policy = "read" }`}, nil)
+	if err != nil {
+		t.Fatalf("cannot create a legacy token: %s", err)
+	}
+
+	entry, _, err := acl.Info(secret, nil)
+	if err != nil {
+		t.Fatalf("cannot read a legacy token: %s", err)
+	}
+	if entry == nil || entry.Name != "legacy" || entry.Type != "client" {
+		t.Fatalf("unexpected legacy token: %#v", entry)
+	}
+
+	tokens, _, err := acl.TokenList(nil)
+	if err != nil {
+		t.Fatalf("cannot list tokens: %s", err)
+	}
+	legacy := 0
+	for _, token := range tokens {
+		if token.Legacy {
+			legacy++
+		}
+	}
+	if legacy != 1 {
+		t.Fatalf("expected exactly 1 legacy token out of %d, got %d", len(tokens), legacy)
+	}
+
+	if _, err = acl.Destroy(secret, nil); err != nil {
+		t.Fatalf("cannot destroy a legacy token: %s", err)
+	}
+	if entry, _, err = acl.Info(secret, nil); err != nil || entry != nil {
+		t.Fatalf("expected the legacy token to be gone, got %#v and %v", entry, err)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	server := fakeconsul.NewServer(masterToken)
+	defer server.Close()
+	server.SetReplicationLag(100 * time.Millisecond)
+
+	acl := newClient(t, server, masterToken).ACL()
+	secondary := &consul.QueryOptions{Datacenter: fakeconsul.SecondaryDatacenter}
+
+	policy, _, err := acl.PolicyCreate(&consul.ACLPolicy{Name: "replicated"}, nil)
+	if err != nil {
+		t.Fatalf("cannot create a policy: %s", err)
+	}
+
+	if _, _, err = acl.PolicyRead(policy.ID, secondary); err == nil || !strings.Contains(err.Error(), "403 (ACL not found)") {
+		t.Fatalf("expected the policy not to be replicated yet, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, _, err = acl.PolicyRead(policy.ID, secondary); err != nil {
+		t.Fatalf("expected the policy to be replicated, got %v", err)
+	}
+
+	status, _, err := acl.Replication(secondary)
+	if err != nil || !status.Enabled || status.ReplicationType != "tokens" {
+		t.Fatalf("expected token replication to be enabled in the secondary datacenter, got %#v and %v", status, err)
+	}
+
+	if _, _, err = acl.Replication(&consul.QueryOptions{Datacenter: "unknown"}); err == nil || !strings.Contains(err.Error(), "No path to datacenter") {
+		t.Fatalf("expected an unknown datacenter to be unreachable, got %v", err)
+	}
+}
+
+func TestLocalTokens(t *testing.T) {
+	server := fakeconsul.NewServer(masterToken)
+	defer server.Close()
+
+	acl := newClient(t, server, masterToken).ACL()
+	primary := &consul.QueryOptions{Datacenter: fakeconsul.PrimaryDatacenter}
+	secondary := &consul.QueryOptions{Datacenter: fakeconsul.SecondaryDatacenter}
+
+	local, _, err := acl.TokenCreate(&consul.ACLToken{Description: "local", Local: true}, &consul.WriteOptions{Datacenter: fakeconsul.SecondaryDatacenter})
+	if err != nil {
+		t.Fatalf("cannot create a local token: %s", err)
+	}
+
+	if _, _, err = acl.TokenRead(local.AccessorID, secondary); err != nil {
+		t.Fatalf("expected the local token to exist in its datacenter, got %v", err)
+	}
+	if _, _, err = acl.TokenRead(local.AccessorID, primary); err == nil || !strings.Contains(err.Error(), "403 (ACL not found)") {
+		t.Fatalf("expected the local token not to exist in another datacenter, got %v", err)
+	}
+
+	tokens, _, err := acl.TokenList(primary)
+	if err != nil {
+		t.Fatalf("cannot list tokens: %s", err)
+	}
+	for _, token := range tokens {
+		if token.AccessorID == local.AccessorID {
+			t.Fatalf("expected the local token not to be listed in another datacenter")
+		}
+	}
+
+	if _, err = acl.TokenDelete(local.AccessorID, &consul.WriteOptions{Datacenter: fakeconsul.PrimaryDatacenter}); err != nil {
+		t.Fatalf("cannot delete a token: %s", err)
+	}
+	if _, _, err = acl.TokenRead(local.AccessorID, secondary); err != nil {
+		t.Fatalf("expected the local token to survive a delete in another datacenter, got %v", err)
+	}
+}
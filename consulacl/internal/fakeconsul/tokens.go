@@ -0,0 +1,372 @@
+package fakeconsul
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+)
+
+const (
+	legacyTypeClient     = "client"
+	legacyTypeManagement = "management"
+)
+
+const (
+	minExpirationTTL = time.Minute
+	maxExpirationTTL = 24 * time.Hour
+)
+
+var serviceNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-_]*[a-z0-9])?$`)
+
+type token struct {
+	consul.ACLToken
+
+	// Type is only set for legacy tokens
+	Type string
+
+	// Local tokens only exist in the datacenter they were created in while global ones are replicated everywhere
+	datacenter string
+}
+
+func (t *token) existsIn(dc string) bool {
+	return !t.Local || t.datacenter == dc
+}
+
+func (s *Server) putToken(t *token) {
+	t.ExpirationTTL = 0
+	t.Hash = hashOf(t.Description, t.Policies, t.Roles, t.ServiceIdentities, t.Local, t.Rules, t.Type)
+	t.ModifyIndex = s.nextIndex()
+	if t.CreateIndex == 0 {
+		t.CreateIndex = t.ModifyIndex
+	}
+	s.recordChange(t.AccessorID, s.tokens[t.AccessorID])
+	s.tokens[t.AccessorID] = t
+}
+
+func (s *Server) tokenBySecret(secret string) *token {
+	for _, t := range s.tokens {
+		if t.SecretID == secret {
+			return t
+		}
+	}
+	return nil
+}
+
+// Consul reaps expired tokens in the background, they are never returned
+func (s *Server) reapExpiredTokens() {
+	now := time.Now()
+	for accessor, t := range s.tokens {
+		if t.ExpirationTime != nil && !now.Before(*t.ExpirationTime) {
+			delete(s.tokens, accessor)
+		}
+	}
+}
+
+// Links are stored by IDs, names are resolved when tokens are read and links to deleted objects are left out
+func (s *Server) tokenView(t *token, showSecret bool) *consul.ACLToken {
+	view := t.ACLToken
+	view.Policies = s.policyLinksView(t.Policies)
+	view.Roles = s.roleLinksView(t.Roles)
+	if !showSecret {
+		view.SecretID = "<hidden>"
+	}
+	return &view
+}
+
+func (s *Server) tokenListEntry(t *token) *consul.ACLTokenListEntry {
+	view := s.tokenView(t, false)
+	return &consul.ACLTokenListEntry{
+		CreateIndex:       view.CreateIndex,
+		ModifyIndex:       view.ModifyIndex,
+		AccessorID:        view.AccessorID,
+		Description:       view.Description,
+		Policies:          view.Policies,
+		Roles:             view.Roles,
+		ServiceIdentities: view.ServiceIdentities,
+		Local:             view.Local,
+		ExpirationTime:    view.ExpirationTime,
+		CreateTime:        view.CreateTime,
+		Hash:              view.Hash,
+		Legacy:            t.Type != "",
+	}
+}
+
+func (s *Server) tokenCreate(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLToken
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	if request.AccessorID == "" {
+		request.AccessorID = generateUUID()
+	} else if !uuidRegexp.MatchString(request.AccessorID) {
+		return nil, fmt.Errorf("Invalid Token: AccessorID is not a valid UUID")
+	} else if _, ok := s.tokens[request.AccessorID]; ok {
+		return nil, fmt.Errorf("Invalid Token: AccessorID is already in use")
+	}
+
+	if request.SecretID == "" {
+		request.SecretID = generateUUID()
+	} else if !uuidRegexp.MatchString(request.SecretID) {
+		return nil, fmt.Errorf("Invalid Token: SecretID is not a valid UUID")
+	} else if s.tokenBySecret(request.SecretID) != nil {
+		return nil, fmt.Errorf("Invalid Token: SecretID is already in use")
+	}
+
+	if request.Rules != "" {
+		return nil, fmt.Errorf("Rules cannot be specified for this token")
+	}
+
+	t := &token{ACLToken: consul.ACLToken{
+		AccessorID:        request.AccessorID,
+		SecretID:          request.SecretID,
+		Description:       request.Description,
+		ServiceIdentities: request.ServiceIdentities,
+		Local:             request.Local,
+		CreateTime:        time.Now(),
+	}}
+	if t.Local {
+		t.datacenter = datacenterOf(r)
+	}
+
+	if err := s.setTokenLinks(t, &request); err != nil {
+		return nil, err
+	}
+
+	if request.ExpirationTTL != 0 || request.ExpirationTime != nil {
+		if request.ExpirationTTL != 0 && request.ExpirationTime != nil {
+			return nil, fmt.Errorf("Token Expiration TTL and Expiration Time cannot both be set")
+		}
+
+		expiration := request.ExpirationTime
+		if expiration == nil {
+			at := t.CreateTime.Add(request.ExpirationTTL)
+			expiration = &at
+		}
+
+		ttl := expiration.Sub(t.CreateTime)
+		if ttl < minExpirationTTL || ttl > maxExpirationTTL {
+			return nil, fmt.Errorf("Token Expiration TTL '%s' should be between %s and %s", ttl, minExpirationTTL, maxExpirationTTL)
+		}
+		t.ExpirationTime = expiration
+	}
+
+	s.putToken(t)
+	return s.tokenView(t, true), nil
+}
+
+func (s *Server) tokenUpdate(r *http.Request, args []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessWrite); err != nil {
+		return nil, err
+	}
+
+	var request consul.ACLToken
+	if err := decodeBody(r, &request); err != nil {
+		return nil, err
+	}
+
+	accessor := args[0]
+	if request.AccessorID != "" && request.AccessorID != accessor {
+		return nil, badRequest("Token update failed: AccessorID doesn't match the URL parameter")
+	}
+
+	existing, ok := s.tokens[accessor]
+	if !ok || !existing.existsIn(datacenterOf(r)) {
+		return nil, fmt.Errorf("Cannot find token %q", accessor)
+	}
+
+	if request.SecretID != "" && request.SecretID != existing.SecretID {
+		return nil, fmt.Errorf("Changing a tokens SecretID is not permitted")
+	}
+	if request.Local != existing.Local {
+		return nil, fmt.Errorf("Cannot toggle local mode of %s", accessor)
+	}
+	if request.ExpirationTTL != 0 ||
+		(request.ExpirationTime != nil && (existing.ExpirationTime == nil || !request.ExpirationTime.Equal(*existing.ExpirationTime))) {
+		return nil, fmt.Errorf("Cannot change expiration time of %s", accessor)
+	}
+	if request.Rules != "" && request.Rules != existing.Rules {
+		return nil, fmt.Errorf("Rules cannot be specified for this token")
+	}
+
+	updated := *existing
+	updated.Description = request.Description
+	updated.ServiceIdentities = request.ServiceIdentities
+
+	if err := s.setTokenLinks(&updated, &request); err != nil {
+		return nil, err
+	}
+
+	s.putToken(&updated)
+	return s.tokenView(&updated, true), nil
+}
+
+func (s *Server) setTokenLinks(t *token, request *consul.ACLToken) error {
+	for _, identity := range request.ServiceIdentities {
+		if err := validateServiceIdentity(identity); err != nil {
+			return err
+		}
+		if request.Local && len(identity.Datacenters) > 0 {
+			return fmt.Errorf("Service identity %q cannot specify a list of datacenters on a local token", identity.ServiceName)
+		}
+	}
+
+	policies, err := s.resolvePolicyLinks(request.Policies)
+	if err != nil {
+		return err
+	}
+	roles, err := s.resolveRoleLinks(request.Roles)
+	if err != nil {
+		return err
+	}
+
+	t.Policies = nil
+	for _, id := range policies {
+		t.Policies = append(t.Policies, &consul.ACLTokenPolicyLink{ID: id})
+	}
+	t.Roles = nil
+	for _, id := range roles {
+		t.Roles = append(t.Roles, &consul.ACLTokenRoleLink{ID: id})
+	}
+
+	return nil
+}
+
+func validateServiceIdentity(identity *consul.ACLServiceIdentity) error {
+	if identity.ServiceName == "" {
+		return fmt.Errorf("Service identity is missing the service name field on this token")
+	}
+	if !serviceNameRegexp.MatchString(identity.ServiceName) {
+		return fmt.Errorf("Service identity %q has an invalid name. Only alphanumeric characters, '-' and '_' are allowed", identity.ServiceName)
+	}
+	return nil
+}
+
+func (s *Server) tokenRead(r *http.Request, args []string) (interface{}, error) {
+	caller, err := s.authorize(r, rules.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := s.tokens[args[0]]
+	if !ok || !t.existsIn(datacenterOf(r)) {
+		return nil, errACLNotFound
+	}
+
+	// Local tokens are never replicated
+	if !t.Local {
+		replicated, _ := s.replicaOf(r, t.AccessorID, t).(*token)
+		if replicated == nil {
+			return nil, errACLNotFound
+		}
+		t = replicated
+	}
+
+	// Secrets are only shown to tokens that could as well change them
+	return s.tokenView(t, s.allows(r, caller, rules.AccessWrite)), nil
+}
+
+// A token can always read itself regardless of its permissions
+func (s *Server) tokenReadSelf(r *http.Request, _ []string) (interface{}, error) {
+	t, err := s.authorize(r, "")
+	if err != nil {
+		return nil, err
+	}
+	return s.tokenView(t, true), nil
+}
+
+func (s *Server) tokenDelete(r *http.Request, args []string) (interface{}, error) {
+	caller, err := s.authorize(r, rules.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor := args[0]
+	if accessor == AnonymousTokenAccessor {
+		return nil, fmt.Errorf("Delete operation not permitted on the anonymous token")
+	}
+	if accessor == caller.AccessorID {
+		return nil, fmt.Errorf("Deletion of the request's authorization token is not permitted")
+	}
+
+	if t, ok := s.tokens[accessor]; ok && t.existsIn(datacenterOf(r)) {
+		delete(s.tokens, accessor)
+	}
+	s.nextIndex()
+	return true, nil
+}
+
+func (s *Server) tokenList(r *http.Request, _ []string) (interface{}, error) {
+	if _, err := s.authorize(r, rules.AccessRead); err != nil {
+		return nil, err
+	}
+
+	query := r.URL.Query()
+	policy, role, authMethod := query.Get("policy"), query.Get("role"), query.Get("authmethod")
+
+	result := make([]*consul.ACLTokenListEntry, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		if !t.existsIn(datacenterOf(r)) {
+			continue
+		}
+		if policy != "" && !hasTokenPolicy(t, policy) {
+			continue
+		}
+		if role != "" && !hasTokenRole(t, role) {
+			continue
+		}
+		if authMethod != "" {
+			// tokens created by logging in are not supported
+			continue
+		}
+		result = append(result, s.tokenListEntry(t))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].AccessorID < result[j].AccessorID })
+	return result, nil
+}
+
+func hasTokenPolicy(t *token, id string) bool {
+	for _, link := range t.Policies {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTokenRole(t *token, id string) bool {
+	for _, link := range t.Roles {
+		if link.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) bootstrap(r *http.Request, _ []string) (interface{}, error) {
+	if s.bootstrapped {
+		return nil, &httpError{http.StatusForbidden, fmt.Sprintf("Permission denied: ACL bootstrap no longer allowed (reset index: %d)", s.index)}
+	}
+	s.bootstrapped = true
+
+	t := &token{ACLToken: consul.ACLToken{
+		AccessorID:  generateUUID(),
+		SecretID:    generateUUID(),
+		Description: "Bootstrap Token (Global Management)",
+		Policies:    []*consul.ACLTokenPolicyLink{{ID: GlobalManagementPolicyID}},
+		CreateTime:  time.Now(),
+	}}
+	s.putToken(t)
+
+	return s.tokenView(t, true), nil
+}
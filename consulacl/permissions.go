@@ -0,0 +1,104 @@
+package consulacl
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+	"log"
+)
+
+// Fetches rules of all given policies and roles and expands service identities into rules of their synthetic policies,
+// identities only grant permissions in the datacenters they're limited to
+func collectRules(client *consul.Client, datacenter string, policyRefs, roleRefs []string, identities []*consul.ACLServiceIdentity) ([]rules.Rules, error) {
+	var result []rules.Rules
+
+	for _, policyRef := range policyRefs {
+		policy, err := readPolicy(client, policyRef)
+		if err != nil {
+			return nil, err
+		}
+		if policy == nil {
+			return nil, &aclError{kind: aclErrorNotFound, cause: fmt.Errorf("ACL policy %q not found", policyRef)}
+		}
+
+		parsed, err := rules.Parse(policy.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rules of ACL policy %q: %s", policy.Name, err)
+		}
+		result = append(result, parsed)
+	}
+
+	for _, roleRef := range roleRefs {
+		role, err := readRole(client, roleRef)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return nil, roleNotFoundError(roleRef)
+		}
+
+		var rolePolicies []string
+		for _, link := range role.Policies {
+			rolePolicies = append(rolePolicies, link.ID)
+		}
+
+		roleRules, err := collectRules(client, datacenter, rolePolicies, nil, role.ServiceIdentities)
+		if err != nil {
+			return nil, fmt.Errorf("error collecting rules of ACL role %q: %w", role.Name, err)
+		}
+		result = append(result, roleRules...)
+	}
+
+	for _, identity := range identities {
+		result = append(result, rules.ServiceIdentity(identity.ServiceName, identity.Datacenters, datacenter))
+	}
+
+	return result, nil
+}
+
+// Fetches rules of everything linked to a token, only legacy tokens have rules embedded
+func collectTokenRules(client *consul.Client, datacenter string, token *consul.ACLToken) ([]rules.Rules, error) {
+	var policies, roles []string
+	for _, link := range token.Policies {
+		policies = append(policies, link.ID)
+	}
+	for _, link := range token.Roles {
+		roles = append(roles, link.ID)
+	}
+
+	result, err := collectRules(client, datacenter, policies, roles, token.ServiceIdentities)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Rules != "" {
+		parsed, err := rules.Parse(token.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rules of legacy ACL token %q: %s", token.AccessorID, err)
+		}
+		// Legacy rules are always prefix matches
+		for i := range parsed {
+			if rules.IsPrefixed(parsed[i].Resource) {
+				parsed[i].Prefix = true
+			}
+		}
+		result = append(result, parsed)
+	}
+
+	return result, nil
+}
+
+// Renders the merged rules a token gets from everything linked to it. They are informational so only an unavailable
+// Consul fails the read while rules that cannot be parsed (e.g. of Consul Enterprise) or policies that cannot be read
+// leave them empty.
+func effectiveTokenRules(client *consul.Client, datacenter string, token *consul.ACLToken) (string, error) {
+	collected, err := collectTokenRules(client, datacenter, token)
+	if err != nil {
+		if isACLErrorKind(err, aclErrorUnavailable) {
+			return "", fmt.Errorf("error computing effective rules of ACL token %q: %s", token.AccessorID, err)
+		}
+		log.Printf("[WARN] Cannot compute effective rules of ACL token %q, leaving them empty: %s", token.AccessorID, err)
+		return "", nil
+	}
+	return rules.Merge(collected...).HCL(), nil
+}
@@ -1,7 +1,9 @@
 package consulacl
 
 import (
+	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/mapstructure"
 )
@@ -19,6 +21,12 @@ func Provider() terraform.ResourceProvider {
 				}, "localhost:8500"),
 			},
 
+			FieldDatacenter: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Default datacenter for resources and data sources, defaults to the datacenter of the agent",
+			},
+
 			// Auth
 
 			"token": {
@@ -64,16 +72,51 @@ func Provider() terraform.ResourceProvider {
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("CONSUL_TLS_SKIP_VERIFY", false),
 			},
+
+			// Retries
+
+			"retry_max_attempts": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				Description:  "Maximum number of attempts for requests failing due to transient issues, 1 disables retries",
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+
+			"retry_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				Description:  "Maximum wait between attempts, waits grow exponentially up to it",
+				ValidateFunc: validateDuration,
+			},
+
+			// Replication
+
+			FieldWaitForReplication: waitForReplicationSchema("Default datacenters to wait for ACL objects to be replicated to"),
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
-			"consulacl_token":          resourceConsulAclToken(),
-			"consulacl_token14":        resourceConsulAclToken14(),
-			"consulacl_policy_binding": resourceConsulAclPolicyBinding(),
+			"consulacl_auth_method":         resourceConsulAclAuthMethod(),
+			"consulacl_binding_rule":        resourceConsulAclBindingRule(),
+			"consulacl_token":               resourceConsulAclToken(),
+			"consulacl_token14":             resourceConsulAclToken14(),
+			"consulacl_token_policies":      resourceConsulAclTokenPolicies(),
+			"consulacl_policy":              resourceConsulAclPolicy(),
+			"consulacl_policy_binding":      resourceConsulAclPolicyBinding(),
+			"consulacl_role":                resourceConsulAclRole(),
+			"consulacl_role_binding":        resourceConsulAclRoleBinding(),
+			"consulacl_role_policy_binding": resourceConsulAclRolePolicyBinding(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"consulacl_token": dataSourceConsulAclToken(),
+			"consulacl_authorize":       dataSourceConsulAclAuthorize(),
+			"consulacl_policies":        dataSourceConsulAclPolicies(),
+			"consulacl_policy":          dataSourceConsulAclPolicy(),
+			"consulacl_policy_document": dataSourceConsulAclPolicyDocument(),
+			"consulacl_role":            dataSourceConsulAclRole(),
+			"consulacl_token":           dataSourceConsulAclToken(),
+			"consulacl_tokens":          dataSourceConsulAclTokens(),
 		},
 
 		ConfigureFunc: configure,
@@ -88,5 +131,20 @@ func configure(d *schema.ResourceData) (interface{}, error) {
 		return nil, err
 	}
 
-	return config.Client()
+	clientConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := consul.NewClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Meta{Client: client, Datacenter: config.Datacenter, config: *clientConfig}
+	if len(config.WaitForReplication) > 0 {
+		meta.WaitForReplication = &config.WaitForReplication[0]
+	}
+
+	return meta, nil
 }
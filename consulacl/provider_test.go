@@ -3,42 +3,49 @@ package consulacl_test
 import (
 	"fmt"
 	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
 	"github.com/hashicorp/terraform/config"
 	"os"
 	"testing"
 
 	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
 
+const testFakeMasterToken = "b3e3bd3c-9e43-4b5a-a7e5-8a8e5c1b7c9d"
+
 var testProviders map[string]terraform.ResourceProvider
 
 var aclProvider *schema.Provider
 var testClient *consul.Client
 
+// Integration tests run against an in-process fake of the Consul ACL API unless TF_ACC is set, in which case a real
+// Consul (e.g. one started with `make test-server`) is used
+var testAgainstFake = os.Getenv(resource.TestEnvVar) == ""
+
+var testFakeServer *fakeconsul.Server
+
 func init() {
 	aclProvider = consulacl.Provider().(*schema.Provider)
 
 	testProviders = map[string]terraform.ResourceProvider{
 		"consulacl": aclProvider,
 	}
+}
 
-	ok := os.Getenv("TF_ACC") == ""
+func TestMain(m *testing.M) {
+	if testAgainstFake {
+		testFakeServer = fakeconsul.NewServer(testFakeMasterToken)
 
-	if os.Getenv("CONSUL_TOKEN") != "" {
-		ok = true
-	}
-	if os.Getenv("CONSUL_HTTP_TOKEN") != "" {
-		ok = true
-	}
-	if !ok {
-		panic("neither CONSUL_TOKEN or CONSUL_HTTP_TOKEN is set for integration tests!")
+		// these take precedence over the CONSUL_HTTP_* ones
+		_ = os.Setenv("CONSUL_ADDRESS", testFakeServer.Address())
+		_ = os.Setenv("CONSUL_SCHEME", "http")
+		_ = os.Setenv("CONSUL_TOKEN", testFakeMasterToken)
 	}
 
-	raw := map[string]interface{}{}
-
-	rawConfig, err := config.NewRawConfig(raw)
+	rawConfig, err := config.NewRawConfig(map[string]interface{}{})
 	if err != nil {
 		panic(fmt.Sprintf("error initializing config for the test provider instance: %s", err))
 	}
@@ -48,7 +55,15 @@ func init() {
 		panic(fmt.Sprintf("error configuring the test provider instance: %s", err))
 	}
 
-	testClient = aclProvider.Meta().(*consul.Client)
+	testClient = aclProvider.Meta().(*consulacl.Meta).Client
+
+	code := m.Run()
+
+	if testFakeServer != nil {
+		testFakeServer.Close()
+	}
+
+	os.Exit(code)
 }
 
 func TestProvider(t *testing.T) {
@@ -0,0 +1,132 @@
+package consulacl
+
+import (
+	"bytes"
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"log"
+	"time"
+)
+
+const replicationPollInterval = 500 * time.Millisecond
+
+// ReplicationWait describes datacenters that objects have to be replicated to before they're considered created
+type ReplicationWait struct {
+	Datacenters []string `mapstructure:"datacenters"`
+	Timeout     string   `mapstructure:"timeout"`
+}
+
+// The same block configures the provider's default and overrides it in resources
+func waitForReplicationSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				FieldDatacenters: {
+					Type:        schema.TypeList,
+					Required:    true,
+					Description: "Secondary datacenters to wait for, an empty list disables waiting",
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				FieldTimeout: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "5m",
+					Description:  "How long to wait for the replication before failing",
+					ValidateFunc: validateDuration,
+				},
+			},
+		},
+	}
+}
+
+// Returns the resource's own settings if it has any, the provider's default otherwise
+func getReplicationWait(d *schema.ResourceData, meta *Meta) (*ReplicationWait, error) {
+	wait := meta.WaitForReplication
+
+	if raw := d.Get(FieldWaitForReplication).([]interface{}); len(raw) > 0 && raw[0] != nil {
+		block := raw[0].(map[string]interface{})
+		wait = &ReplicationWait{Timeout: block[FieldTimeout].(string)}
+		for _, dc := range block[FieldDatacenters].([]interface{}) {
+			wait.Datacenters = append(wait.Datacenters, dc.(string))
+		}
+	}
+
+	if wait == nil || len(wait.Datacenters) == 0 {
+		return nil, nil
+	}
+	if _, err := time.ParseDuration(wait.Timeout); err != nil {
+		return nil, fmt.Errorf("error parsing %q of %q: %s", FieldTimeout, FieldWaitForReplication, err)
+	}
+
+	return wait, nil
+}
+
+// Polls the object in every datacenter until its hash matches the one in the datacenter it's managed in, that is read
+// with nil options. The read function should return the hash of the object or nil if the object isn't there yet.
+// Tokens are only replicated when token replication is enabled on top of the replication of policies and roles.
+func waitForReplication(client *consul.Client, wait *ReplicationWait, kind string, requireTokens bool, read func(*consul.QueryOptions) ([]byte, error)) error {
+	if wait == nil {
+		return nil
+	}
+
+	hash, err := read(nil)
+	if err != nil {
+		return fmt.Errorf("error reading the %s: %s", kind, err)
+	}
+	if hash == nil {
+		return fmt.Errorf("the %s disappeared before it was replicated", kind)
+	}
+
+	timeout, _ := time.ParseDuration(wait.Timeout)
+	deadline := time.Now().Add(timeout)
+
+	for _, dc := range wait.Datacenters {
+		if err := checkReplication(client, dc, requireTokens); err != nil {
+			return err
+		}
+
+		for {
+			replicated, err := read(&consul.QueryOptions{Datacenter: dc})
+			if err != nil {
+				return fmt.Errorf("error reading the %s in datacenter %q: %s", kind, dc, err)
+			}
+			if replicated != nil && bytes.Equal(replicated, hash) {
+				break
+			}
+
+			if time.Now().After(deadline) {
+				return fmt.Errorf("the %s was not replicated to datacenter %q within %s", kind, dc, wait.Timeout)
+			}
+
+			log.Printf("[DEBUG] Waiting for the %s to be replicated to datacenter %q", kind, dc)
+			time.Sleep(replicationPollInterval)
+		}
+	}
+
+	return nil
+}
+
+// Fails early when objects would never show up in the datacenter
+func checkReplication(client *consul.Client, dc string, requireTokens bool) error {
+	status, _, err := client.ACL().Replication(&consul.QueryOptions{Datacenter: dc})
+	if err != nil {
+		return fmt.Errorf("error reading ACL replication status of datacenter %q: %s", dc, classifyACLError(client, err))
+	}
+
+	// the primary datacenter doesn't replicate but has everything anyway
+	if !status.Enabled && status.SourceDatacenter != dc {
+		return fmt.Errorf("ACL replication is not enabled in datacenter %q", dc)
+	}
+	if status.Enabled && requireTokens && status.ReplicationType != "tokens" {
+		return fmt.Errorf("ACL token replication is not enabled in datacenter %q, see `acl.enable_token_replication`", dc)
+	}
+
+	return nil
+}
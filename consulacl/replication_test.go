@@ -0,0 +1,136 @@
+package consulacl_test
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/internal/fakeconsul"
+	"regexp"
+	"testing"
+	"time"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const replicationResources = `
+resource "consulacl_policy" "test" {
+	name = "test-replication"
+	description = "%[1]s"
+	rules = "operator = \"read\""
+	%[2]s
+}
+
+resource "consulacl_role" "test" {
+	name = "test-replication"
+	description = "%[1]s"
+	policies = ["${consulacl_policy.test.name}"]
+	%[2]s
+}
+
+resource "consulacl_token14" "test" {
+	accessor = "8f2e4d6c-1b3a-4c5e-9d7f-0a2b4c6d8e1f"
+	description = "%[1]s"
+	roles = ["${consulacl_role.test.name}"]
+	%[2]s
+}
+`
+
+const replicationWait = `
+	wait_for_replication {
+		datacenters = ["dc2"]
+	}
+`
+
+var replicationConfig = fmt.Sprintf(replicationResources, "Test Replication", replicationWait)
+
+var replicationConfigProviderDefault = `provider "consulacl" {` + replicationWait + `}` +
+	fmt.Sprintf(replicationResources, "Test Replication Updated", "")
+
+func testSecondaryDatacenterPreCheck(t *testing.T) {
+	if !testAgainstFake {
+		t.Skip("requires the secondary datacenter of the fake Consul")
+	}
+	testResourcePreConfig(t)
+}
+
+func TestIntegrationReplication(t *testing.T) {
+	testFakeServer.SetReplicationLag(300 * time.Millisecond)
+	defer testFakeServer.SetReplicationLag(0)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testSecondaryDatacenterPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: replicationConfig,
+				Check:  testReplicated(fakeconsul.SecondaryDatacenter, "Test Replication"),
+			},
+			{
+				Config: replicationConfigProviderDefault,
+				Check:  testReplicated(fakeconsul.SecondaryDatacenter, "Test Replication Updated"),
+			},
+		},
+	})
+}
+
+func TestIntegrationReplicationTimeout(t *testing.T) {
+	testFakeServer.SetReplicationLag(time.Hour)
+	defer testFakeServer.SetReplicationLag(0)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testSecondaryDatacenterPreCheck(t) },
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "consulacl_policy" "test" {
+	name = "test-replication-timeout"
+	rules = "operator = \"read\""
+
+	wait_for_replication {
+		datacenters = ["dc2"]
+		timeout = "1s"
+	}
+}
+`,
+				ExpectError: regexp.MustCompile(`the ACL policy was not replicated to datacenter "dc2" within 1s`),
+			},
+		},
+	})
+}
+
+// Objects have to be in the datacenter right after they're applied
+func testReplicated(dc, description string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		q := &consul.QueryOptions{Datacenter: dc}
+		resources := s.RootModule().Resources
+
+		policy, _, err := testClient.ACL().PolicyRead(resources["consulacl_policy.test"].Primary.ID, q)
+		if err != nil {
+			return fmt.Errorf("error reading ACL policy in datacenter %q: %s", dc, err)
+		}
+		if policy.Description != description {
+			return fmt.Errorf("ACL policy in datacenter %q has description %q, expected %q", dc, policy.Description, description)
+		}
+
+		role, _, err := testClient.ACL().RoleRead(resources["consulacl_role.test"].Primary.ID, q)
+		if err != nil {
+			return fmt.Errorf("error reading ACL role in datacenter %q: %s", dc, err)
+		}
+		if role == nil || role.Description != description {
+			return fmt.Errorf("ACL role in datacenter %q is %#v, expected description %q", dc, role, description)
+		}
+
+		token, _, err := testClient.ACL().TokenRead(resources["consulacl_token14.test"].Primary.ID, q)
+		if err != nil {
+			return fmt.Errorf("error reading ACL token in datacenter %q: %s", dc, err)
+		}
+		if token.Description != description {
+			return fmt.Errorf("ACL token in datacenter %q has description %q, expected %q", dc, token.Description, description)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,575 @@
+package consulacl
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
+)
+
+const authMethodTypeKubernetes = "kubernetes"
+const authMethodTypeJWT = "jwt"
+const authMethodTypeOIDC = "oidc"
+
+var authMethodTypes = []string{authMethodTypeKubernetes, authMethodTypeJWT, authMethodTypeOIDC}
+
+// authMethodField maps an attribute of a typed auth method block onto a key of Consul's free-form auth method config
+type authMethodField struct {
+	key    string
+	schema *schema.Schema
+}
+
+var jwtAuthMethodFields = map[string]authMethodField{
+	"jwt_validation_pub_keys": {"JWTValidationPubKeys", pemListSchema(validatePEMPublicKey)},
+	"jwks_url":                {"JWKSURL", stringSchema()},
+	"jwks_ca_cert":            {"JWKSCACert", pemSchema(validatePEMCertificate)},
+	"oidc_discovery_url":      {"OIDCDiscoveryURL", stringSchema()},
+	"oidc_discovery_ca_cert":  {"OIDCDiscoveryCACert", pemSchema(validatePEMCertificate)},
+	"bound_issuer":            {"BoundIssuer", stringSchema()},
+	"bound_audiences":         {"BoundAudiences", stringListSchema()},
+	"claim_mappings":          {"ClaimMappings", stringMapSchema()},
+	"list_claim_mappings":     {"ListClaimMappings", stringMapSchema()},
+	"jwt_supported_algs":      {"JWTSupportedAlgs", stringListSchema()},
+	"expiration_leeway":       {"ExpirationLeeway", durationSchema()},
+	"not_before_leeway":       {"NotBeforeLeeway", durationSchema()},
+	"clock_skew_leeway":       {"ClockSkewLeeway", durationSchema()},
+}
+
+var oidcAuthMethodFields = map[string]authMethodField{
+	"oidc_discovery_url":     {"OIDCDiscoveryURL", stringSchema()},
+	"oidc_discovery_ca_cert": {"OIDCDiscoveryCACert", pemSchema(validatePEMCertificate)},
+	"oidc_client_id":         {"OIDCClientID", stringSchema()},
+	"oidc_client_secret":     {"OIDCClientSecret", sensitiveStringSchema()},
+	"oidc_scopes":            {"OIDCScopes", stringListSchema()},
+	"allowed_redirect_uris":  {"AllowedRedirectURIs", stringListSchema()},
+	"bound_audiences":        {"BoundAudiences", stringListSchema()},
+	"claim_mappings":         {"ClaimMappings", stringMapSchema()},
+	"list_claim_mappings":    {"ListClaimMappings", stringMapSchema()},
+	"jwt_supported_algs":     {"JWTSupportedAlgs", stringListSchema()},
+	"verbose_oidc_logging":   {"VerboseOIDCLogging", boolSchema()},
+}
+
+func resourceConsulAclAuthMethod() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclAuthMethodCreate,
+		Read:   resourceConsulAclAuthMethodRead,
+		Update: resourceConsulAclAuthMethodUpdate,
+		Delete: resourceConsulAclAuthMethodDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: diffAuthMethodResource,
+
+		Schema: map[string]*schema.Schema{
+			FieldName: {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			FieldType: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldKubernetes: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{FieldJWT, FieldOIDC},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						FieldHost: {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						FieldCACert: {
+							Type:         schema.TypeString,
+							Required:     true,
+							StateFunc:    normalizePEM,
+							ValidateFunc: validatePEMCertificate,
+						},
+						FieldServiceAccountJWT: {
+							Type:         schema.TypeString,
+							Required:     true,
+							Sensitive:    true,
+							StateFunc:    normalizeJWT,
+							ValidateFunc: validateJWT,
+						},
+					},
+				},
+			},
+			FieldJWT: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{FieldKubernetes, FieldOIDC},
+				Elem:          &schema.Resource{Schema: authMethodFieldsSchema(jwtAuthMethodFields)},
+			},
+			FieldOIDC: {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{FieldKubernetes, FieldJWT},
+				Elem:          &schema.Resource{Schema: authMethodFieldsSchema(oidcAuthMethodFields)},
+			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclAuthMethodCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	method, err := getAuthMethod(d)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.ACL().AuthMethodCreate(method, nil)
+	if err != nil {
+		return fmt.Errorf("error creating ACL auth method %q: %s", method.Name, classifyACLError(client, err))
+	}
+
+	d.SetId(method.Name)
+
+	return resourceConsulAclAuthMethodRead(d, meta)
+}
+
+func resourceConsulAclAuthMethodRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	method, _, err := client.ACL().AuthMethodRead(id, nil)
+	if err != nil {
+		return fmt.Errorf("error reading ACL auth method %q: %s", id, classifyACLError(client, err))
+	}
+
+	if method == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if err = d.Set(FieldName, method.Name); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldName, err)
+	}
+
+	if err = d.Set(FieldDescription, method.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	if err = d.Set(FieldType, method.Type); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldType, err)
+	}
+
+	blocks := map[string][]map[string]interface{}{}
+
+	switch method.Type {
+	case authMethodTypeKubernetes:
+		config, err := consul.ParseKubernetesAuthMethodConfig(method.Config)
+		if err != nil {
+			return fmt.Errorf("error parsing config of ACL auth method %q: %s", id, err)
+		}
+
+		jwt := normalizeJWT(config.ServiceAccountJWT)
+		if jwt == "" {
+			// keep the secret if Consul redacts it
+			jwt = d.Get(FieldKubernetes + ".0." + FieldServiceAccountJWT).(string)
+		}
+
+		blocks[FieldKubernetes] = []map[string]interface{}{{
+			FieldHost:              config.Host,
+			FieldCACert:            normalizePEM(config.CACert),
+			FieldServiceAccountJWT: jwt,
+		}}
+	case authMethodTypeJWT:
+		blocks[FieldJWT] = []map[string]interface{}{flattenAuthMethodConfig(d, FieldJWT, jwtAuthMethodFields, method.Config)}
+	case authMethodTypeOIDC:
+		blocks[FieldOIDC] = []map[string]interface{}{flattenAuthMethodConfig(d, FieldOIDC, oidcAuthMethodFields, method.Config)}
+	default:
+		return fmt.Errorf("ACL auth method %q has unsupported type %q", id, method.Type)
+	}
+
+	for _, field := range authMethodTypes {
+		if err = d.Set(field, blocks[field]); err != nil {
+			return fmt.Errorf("error while setting %q: %s", field, err)
+		}
+	}
+
+	return nil
+}
+
+func resourceConsulAclAuthMethodUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	method, err := getAuthMethod(d)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.ACL().AuthMethodUpdate(method, nil)
+	if err != nil {
+		return fmt.Errorf("error updating ACL auth method %q: %s", method.Name, classifyACLError(client, err))
+	}
+
+	return resourceConsulAclAuthMethodRead(d, meta)
+}
+
+func resourceConsulAclAuthMethodDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	_, err = client.ACL().AuthMethodDelete(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting ACL auth method %q: %s", id, err)
+	}
+
+	return nil
+}
+
+func getAuthMethod(d *schema.ResourceData) (*consul.ACLAuthMethod, error) {
+	method := &consul.ACLAuthMethod{
+		Name:        d.Get(FieldName).(string),
+		Description: d.Get(FieldDescription).(string),
+	}
+
+	if raw := d.Get(FieldKubernetes).([]interface{}); len(raw) > 0 && raw[0] != nil {
+		definition := raw[0].(map[string]interface{})
+		config := consul.KubernetesAuthMethodConfig{
+			Host:              definition[FieldHost].(string),
+			CACert:            definition[FieldCACert].(string),
+			ServiceAccountJWT: definition[FieldServiceAccountJWT].(string),
+		}
+		method.Type = authMethodTypeKubernetes
+		method.Config = config.RenderToConfig()
+	} else if raw := d.Get(FieldJWT).([]interface{}); len(raw) > 0 && raw[0] != nil {
+		method.Type = authMethodTypeJWT
+		method.Config = expandAuthMethodConfig(jwtAuthMethodFields, raw[0].(map[string]interface{}))
+	} else if raw := d.Get(FieldOIDC).([]interface{}); len(raw) > 0 && raw[0] != nil {
+		method.Type = authMethodTypeOIDC
+		method.Config = expandAuthMethodConfig(oidcAuthMethodFields, raw[0].(map[string]interface{}))
+	} else {
+		return nil, fmt.Errorf("exactly one of %s must be defined for ACL auth method %q", strings.Join(authMethodTypes, ", "), method.Name)
+	}
+
+	return method, nil
+}
+
+// Validates that exactly one auth method type is defined and replaces the auth method once the type changes
+func diffAuthMethodResource(d *schema.ResourceDiff, m interface{}) error {
+	var defined []string
+	for _, field := range authMethodTypes {
+		if len(d.Get(field).([]interface{})) > 0 {
+			defined = append(defined, field)
+		}
+	}
+
+	if len(defined) != 1 {
+		return fmt.Errorf("exactly one of %s must be defined, got: %v", strings.Join(authMethodTypes, ", "), defined)
+	}
+
+	if d.Get(FieldType).(string) == defined[0] {
+		return nil
+	}
+
+	if err := d.SetNew(FieldType, defined[0]); err != nil {
+		return err
+	}
+
+	if d.Id() == "" {
+		return nil
+	}
+
+	return d.ForceNew(FieldType)
+}
+
+func authMethodFieldsSchema(fields map[string]authMethodField) map[string]*schema.Schema {
+	result := make(map[string]*schema.Schema, len(fields))
+	for name, field := range fields {
+		result[name] = field.schema
+	}
+	return result
+}
+
+func expandAuthMethodConfig(fields map[string]authMethodField, definition map[string]interface{}) map[string]interface{} {
+	config := make(map[string]interface{})
+
+	for name, field := range fields {
+		value, ok := definition[name]
+		if !ok {
+			continue
+		}
+
+		switch field.schema.Type {
+		case schema.TypeString:
+			if value.(string) != "" {
+				config[field.key] = value
+			}
+		case schema.TypeBool:
+			if value.(bool) {
+				config[field.key] = value
+			}
+		case schema.TypeList, schema.TypeMap:
+			if collectionLen(value) > 0 {
+				config[field.key] = value
+			}
+		}
+	}
+
+	return config
+}
+
+// Converts the free-form config returned by Consul into a normalized block so that it doesn't produce spurious diffs
+func flattenAuthMethodConfig(d *schema.ResourceData, block string, fields map[string]authMethodField, config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+
+	for name, field := range fields {
+		value := config[field.key]
+
+		switch field.schema.Type {
+		case schema.TypeString:
+			str, _ := value.(string)
+			if field.schema.StateFunc != nil {
+				str = field.schema.StateFunc(str)
+			}
+			if str == "" && field.schema.Sensitive {
+				// keep the secret if Consul redacts it
+				str = d.Get(block + ".0." + name).(string)
+			}
+			result[name] = str
+		case schema.TypeBool:
+			b, _ := value.(bool)
+			result[name] = b
+		case schema.TypeList:
+			var list []string
+			raw, _ := value.([]interface{})
+			for _, item := range raw {
+				str := fmt.Sprintf("%v", item)
+				if field.schema.Elem.(*schema.Schema).StateFunc != nil {
+					str = field.schema.Elem.(*schema.Schema).StateFunc(str)
+				}
+				list = append(list, str)
+			}
+			result[name] = list
+		case schema.TypeMap:
+			m := make(map[string]string)
+			raw, _ := value.(map[string]interface{})
+			for k, v := range raw {
+				m[k] = fmt.Sprintf("%v", v)
+			}
+			result[name] = m
+		}
+	}
+
+	return result
+}
+
+func collectionLen(value interface{}) int {
+	switch v := value.(type) {
+	case []interface{}:
+		return len(v)
+	case map[string]interface{}:
+		return len(v)
+	}
+	return 0
+}
+
+func stringSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+}
+
+func sensitiveStringSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:      schema.TypeString,
+		Optional:  true,
+		Sensitive: true,
+	}
+}
+
+func durationSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validateDuration,
+	}
+}
+
+func boolSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+	}
+}
+
+func stringListSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+}
+
+func stringMapSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+}
+
+func pemSchema(validate schema.SchemaValidateFunc) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		StateFunc:    normalizePEM,
+		ValidateFunc: validate,
+	}
+}
+
+func pemListSchema(validate schema.SchemaValidateFunc) *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type:         schema.TypeString,
+			StateFunc:    normalizePEM,
+			ValidateFunc: validate,
+		},
+	}
+}
+
+// Re-encodes PEM blocks so that formatting differences such as line wrapping and trailing whitespace don't matter
+func normalizePEM(v interface{}) string {
+	raw := strings.TrimSpace(v.(string))
+
+	var blocks []string
+	rest := []byte(raw)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, string(pem.EncodeToMemory(block)))
+	}
+
+	if len(blocks) == 0 || strings.TrimSpace(string(rest)) != "" {
+		// not a valid PEM - let validation report it
+		return raw
+	}
+
+	return strings.Join(blocks, "")
+}
+
+func normalizeJWT(v interface{}) string {
+	return strings.TrimSpace(v.(string))
+}
+
+func decodePEMBlocks(k string, raw string, expectedType string) ([]*pem.Block, error) {
+	var blocks []*pem.Block
+	rest := []byte(strings.TrimSpace(raw))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, fmt.Errorf("%q must contain only PEM encoded data", k)
+		}
+		if block.Type != expectedType {
+			return nil, fmt.Errorf("%q contains a PEM block of unexpected type %q, expected %q", k, block.Type, expectedType)
+		}
+		blocks = append(blocks, block)
+		rest = []byte(strings.TrimSpace(string(rest)))
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("%q must contain at least one PEM encoded %s", k, expectedType)
+	}
+
+	return blocks, nil
+}
+
+func validatePEMCertificate(v interface{}, k string) (ws []string, errors []error) {
+	blocks, err := decodePEMBlocks(k, v.(string), "CERTIFICATE")
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	for _, block := range blocks {
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			errors = append(errors, fmt.Errorf("%q contains an invalid certificate: %s", k, err))
+		}
+	}
+	return
+}
+
+func validatePEMPublicKey(v interface{}, k string) (ws []string, errors []error) {
+	blocks, err := decodePEMBlocks(k, v.(string), "PUBLIC KEY")
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	for _, block := range blocks {
+		if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			errors = append(errors, fmt.Errorf("%q contains an invalid public key: %s", k, err))
+		}
+	}
+	return
+}
+
+// Checks that the value is a well-formed JWT, i.e. it has a JSON header and payload, but doesn't verify its signature
+func validateJWT(v interface{}, k string) (ws []string, errors []error) {
+	parts := strings.Split(normalizeJWT(v), ".")
+	if len(parts) != 3 {
+		return nil, []error{fmt.Errorf("%q must be a JWT consisting of 3 dot-separated parts, got %d", k, len(parts))}
+	}
+
+	for i, name := range []string{"header", "payload"} {
+		decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			errors = append(errors, fmt.Errorf("%q has a JWT %s that is not base64url encoded: %s", k, name, err))
+			continue
+		}
+
+		var claims map[string]interface{}
+		if err := json.Unmarshal(decoded, &claims); err != nil {
+			errors = append(errors, fmt.Errorf("%q has a JWT %s that is not a JSON object: %s", k, name, err))
+		}
+	}
+
+	if _, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[2], "=")); err != nil {
+		errors = append(errors, fmt.Errorf("%q has a JWT signature that is not base64url encoded: %s", k, err))
+	}
+	return
+}
@@ -0,0 +1,210 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"regexp"
+	"strings"
+)
+
+// Variables available to selectors and bind names of binding rules depending on the auth method type:
+// `serviceaccount.*` for kubernetes, `value.*` and `list.*` (selectors only) for jwt and oidc
+var bindingRuleSelectorRegexp = regexp.MustCompile(`^(serviceaccount\.(namespace|name|uid)|(value|list)\.[^.]+)$`)
+var bindingRuleVariableRegexp = regexp.MustCompile(`^(serviceaccount\.(namespace|name|uid)|value\.[^.]+)$`)
+var bindingRuleInterpolationRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+func resourceConsulAclBindingRule() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclBindingRuleCreate,
+		Read:   resourceConsulAclBindingRuleRead,
+		Update: resourceConsulAclBindingRuleUpdate,
+		Delete: resourceConsulAclBindingRuleDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldAuthMethod: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the auth method the rule applies to",
+			},
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			FieldSelector: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateBindingRuleSelector,
+			},
+			FieldBindType: {
+				Type:     schema.TypeString,
+				Required: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(consul.BindingRuleBindTypeService),
+					string(consul.BindingRuleBindTypeRole),
+				}, false),
+			},
+			FieldBindName: {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateBindingRuleBindName,
+			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclBindingRuleCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	rule := getBindingRule(d)
+
+	created, _, err := client.ACL().BindingRuleCreate(rule, nil)
+	if err != nil {
+		return fmt.Errorf("error creating ACL binding rule for the auth method %q: %s", rule.AuthMethod, classifyACLError(client, err))
+	}
+
+	d.SetId(created.ID)
+
+	return resourceConsulAclBindingRuleRead(d, meta)
+}
+
+func resourceConsulAclBindingRuleRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	rule, _, err := client.ACL().BindingRuleRead(id, nil)
+	if err != nil {
+		return fmt.Errorf("error reading ACL binding rule %q: %s", id, classifyACLError(client, err))
+	}
+
+	if rule == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if err = d.Set(FieldAuthMethod, rule.AuthMethod); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldAuthMethod, err)
+	}
+
+	if err = d.Set(FieldDescription, rule.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	if err = d.Set(FieldSelector, rule.Selector); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldSelector, err)
+	}
+
+	if err = d.Set(FieldBindType, string(rule.BindType)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldBindType, err)
+	}
+
+	if err = d.Set(FieldBindName, rule.BindName); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldBindName, err)
+	}
+
+	return nil
+}
+
+func resourceConsulAclBindingRuleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	rule := getBindingRule(d)
+	rule.ID = d.Id()
+
+	_, _, err = client.ACL().BindingRuleUpdate(rule, nil)
+	if err != nil {
+		return fmt.Errorf("error updating ACL binding rule %q: %s", rule.ID, classifyACLError(client, err))
+	}
+
+	return resourceConsulAclBindingRuleRead(d, meta)
+}
+
+func resourceConsulAclBindingRuleDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	_, err = client.ACL().BindingRuleDelete(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting ACL binding rule %q: %s", id, err)
+	}
+
+	return nil
+}
+
+func getBindingRule(d *schema.ResourceData) *consul.ACLBindingRule {
+	return &consul.ACLBindingRule{
+		AuthMethod:  d.Get(FieldAuthMethod).(string),
+		Description: d.Get(FieldDescription).(string),
+		Selector:    d.Get(FieldSelector).(string),
+		BindType:    consul.BindingRuleBindType(d.Get(FieldBindType).(string)),
+		BindName:    d.Get(FieldBindName).(string),
+	}
+}
+
+func validateBindingRuleSelector(v interface{}, k string) (ws []string, errors []error) {
+	expression := v.(string)
+	if strings.TrimSpace(expression) == "" {
+		return
+	}
+
+	selectors, err := parseSelector(expression)
+	if err != nil {
+		return nil, []error{fmt.Errorf("%q is not a valid selector: %s", k, err)}
+	}
+
+	for _, selector := range selectors {
+		if !bindingRuleSelectorRegexp.MatchString(selector) {
+			errors = append(errors, fmt.Errorf(
+				"%q refers to an unknown selector %q, expected one of: serviceaccount.namespace, serviceaccount.name, serviceaccount.uid, value.<claim>, list.<claim>",
+				k, selector,
+			))
+		}
+	}
+	return
+}
+
+func validateBindingRuleBindName(v interface{}, k string) (ws []string, errors []error) {
+	bindName := v.(string)
+	if bindName == "" {
+		return nil, []error{fmt.Errorf("%q must not be empty", k)}
+	}
+
+	for _, match := range bindingRuleInterpolationRegexp.FindAllStringSubmatch(bindName, -1) {
+		variable := strings.TrimSpace(match[1])
+		if !bindingRuleVariableRegexp.MatchString(variable) {
+			errors = append(errors, fmt.Errorf(
+				"%q refers to an unknown variable %q, expected one of: serviceaccount.namespace, serviceaccount.name, serviceaccount.uid, value.<claim>",
+				k, variable,
+			))
+		}
+	}
+
+	if strings.Contains(bindingRuleInterpolationRegexp.ReplaceAllString(bindName, ""), "${") {
+		errors = append(errors, fmt.Errorf("%q has an unterminated interpolation: %q", k, bindName))
+	}
+	return
+}
@@ -0,0 +1,114 @@
+package consulacl_test
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"regexp"
+	"testing"
+)
+
+const resourceAclBindingRuleConfigTemplate = `
+resource "consulacl_auth_method" "test" {
+	name = "test-binding-rule"
+
+	kubernetes {
+		host = "https://kubernetes.default.svc:443"
+		ca_cert = <<EOF
+%s
+EOF
+		service_account_jwt = "%s"
+	}
+}
+
+resource "consulacl_binding_rule" "test" {
+	auth_method = "${consulacl_auth_method.test.name}"
+	description = "Test Binding Rule"
+	selector = "%s"
+	bind_type = "%s"
+	bind_name = "%s"
+}
+`
+
+func testBindingRuleConfig(selector, bindType, bindName string) string {
+	return fmt.Sprintf(resourceAclBindingRuleConfigTemplate, testKubernetesCACert, testKubernetesJWT, selector, bindType, bindName)
+}
+
+func TestIntegrationResourceBindingRule(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceBindingRulesAbsent("test-binding-rule"),
+		Steps: []resource.TestStep{
+			{
+				Config: testBindingRuleConfig("serviceaccount.namespace==default", "service", "$${serviceaccount.name}"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldAuthMethod, "test-binding-rule"),
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldSelector, "serviceaccount.namespace==default"),
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldBindType, "service"),
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldBindName, "${serviceaccount.name}"),
+				),
+			},
+			{
+				Config: testBindingRuleConfig("serviceaccount.namespace==default and serviceaccount.name!=vault", "role", "admins"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldBindType, "role"),
+					resource.TestCheckResourceAttr("consulacl_binding_rule.test", consulacl.FieldBindName, "admins"),
+				),
+			},
+			{
+				ResourceName:      "consulacl_binding_rule.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceBindingRuleValidation(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      testBindingRuleConfig("serviceaccount.namespace = default", "service", "web"),
+				ExpectError: regexp.MustCompile("is not a valid selector"),
+			},
+			{
+				Config:      testBindingRuleConfig("serviceaccount.namespce == default", "service", "web"),
+				ExpectError: regexp.MustCompile("refers to an unknown selector"),
+			},
+			{
+				Config:      testBindingRuleConfig("", "service", "$${serviceaccount.nme}"),
+				ExpectError: regexp.MustCompile("refers to an unknown variable"),
+			},
+			{
+				Config:      testBindingRuleConfig("", "node", "web"),
+				ExpectError: regexp.MustCompile("expected bind_type to be one of"),
+			},
+		},
+	})
+}
+
+func testResourceBindingRulesAbsent(authMethod string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		method, _, err := testClient.ACL().AuthMethodRead(authMethod, nil)
+		if err != nil {
+			return err
+		}
+		if method == nil {
+			// binding rules are deleted together with their auth method
+			return nil
+		}
+
+		rules, _, err := testClient.ACL().BindingRuleList(authMethod, nil)
+		if err != nil {
+			return fmt.Errorf("error listing ACL binding rules in Consul: %s", err)
+		}
+		if len(rules) > 0 {
+			return fmt.Errorf("binding rules of the auth method %q weren't deleted from Consul", authMethod)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,276 @@
+package consulacl
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulAclPolicy() *schema.Resource {
+	rule := ruleSchema(policySingletonScopes)
+	rule.ConflictsWith = []string{FieldRules}
+
+	return &schema.Resource{
+		Create: resourceConsulAclPolicyCreate,
+		Read:   resourceConsulAclPolicyRead,
+		Update: resourceConsulAclPolicyUpdate,
+		Delete: resourceConsulAclPolicyDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		CustomizeDiff: diffPolicyResource,
+
+		Schema: map[string]*schema.Schema{
+			FieldName: {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			FieldRules: rulesSchema(),
+			FieldRule:  rule,
+			FieldDatacenters: {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			FieldWaitForReplication: waitForReplicationSchema("Datacenters to wait for the policy to be replicated to, overrides the provider's default"),
+			FieldDatacenter:         datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	policy, err := getPolicy(d)
+	if err != nil {
+		return err
+	}
+
+	created, _, err := client.ACL().PolicyCreate(policy, nil)
+	if err != nil {
+		return fmt.Errorf("error creating ACL policy %q: %s", policy.Name, classifyACLError(client, err))
+	}
+
+	d.SetId(created.ID)
+
+	if err = waitForPolicyReplication(d, meta); err != nil {
+		return err
+	}
+
+	return resourceConsulAclPolicyRead(d, meta)
+}
+
+func resourceConsulAclPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	policy, _, err := client.ACL().PolicyRead(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error reading ACL policy %q: %s", id, err)
+	}
+
+	if err = d.Set(FieldName, policy.Name); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldName, err)
+	}
+
+	if err = d.Set(FieldDescription, policy.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	if err = d.Set(FieldRules, normalizeRules(policy.Rules)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldRules, err)
+	}
+
+	// Structured rules are only tracked when they are used to define the policy, otherwise raw rules are the source of truth
+	if d.Get(FieldRule).(*schema.Set).Len() > 0 {
+		definitions, err := decodePolicyRules(policy.Rules)
+		if err != nil {
+			return fmt.Errorf("error decoding rules of ACL policy %q: %s", id, err)
+		}
+
+		if err = d.Set(FieldRule, definitions); err != nil {
+			return fmt.Errorf("error while setting %q: %s", FieldRule, err)
+		}
+	}
+
+	if err = d.Set(FieldDatacenters, policy.Datacenters); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDatacenters, err)
+	}
+
+	return nil
+}
+
+func resourceConsulAclPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	policy, err := getPolicy(d)
+	if err != nil {
+		return err
+	}
+	policy.ID = d.Id()
+
+	_, _, err = client.ACL().PolicyUpdate(policy, nil)
+	if err != nil {
+		return fmt.Errorf("error updating ACL policy %q: %s", policy.ID, classifyACLError(client, err))
+	}
+
+	if err = waitForPolicyReplication(d, meta); err != nil {
+		return err
+	}
+
+	return resourceConsulAclPolicyRead(d, meta)
+}
+
+func waitForPolicyReplication(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	wait, err := getReplicationWait(d, meta.(*Meta))
+	if err != nil {
+		return err
+	}
+
+	return waitForReplication(client, wait, "ACL policy", false, func(q *consul.QueryOptions) ([]byte, error) {
+		policy, _, err := client.ACL().PolicyRead(d.Id(), q)
+		if err != nil {
+			err = classifyACLError(client, err)
+			if isNotFoundError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return policy.Hash, nil
+	})
+}
+
+func resourceConsulAclPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	_, err = client.ACL().PolicyDelete(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting ACL policy %q: %s", id, err)
+	}
+
+	return nil
+}
+
+func getPolicy(d *schema.ResourceData) (*consul.ACLPolicy, error) {
+	// Post-1.4 ACL policies treat `key "foo"` as an exact match so prefixes have to be expressed as `key_prefix "foo"`
+	encoded, err := getRules(d, policySingletonScopes, true)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &consul.ACLPolicy{
+		Name:        d.Get(FieldName).(string),
+		Description: d.Get(FieldDescription).(string),
+		Rules:       encoded,
+	}
+
+	for _, dc := range d.Get(FieldDatacenters).(*schema.Set).List() {
+		policy.Datacenters = append(policy.Datacenters, dc.(string))
+	}
+
+	return policy, nil
+}
+
+// Rule blocks can only express prefix matches, exact matches are left out so that they show up as a drift
+func decodePolicyRules(raw string) ([]map[string]string, error) {
+	parsed, err := rules.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixed rules.Rules
+	for _, rule := range parsed {
+		if rules.IsPrefixed(rule.Resource) && !rule.Prefix {
+			continue
+		}
+		prefixed = append(prefixed, rule)
+	}
+
+	return flattenRules(prefixed), nil
+}
+
+// Validates structured rules and makes sure raw rules are re-computed once they change
+func diffPolicyResource(d *schema.ResourceDiff, m interface{}) error {
+	_, newRules := d.GetChange(FieldRule)
+
+	_, err := extractRules(newRules.(*schema.Set).List(), policySingletonScopes, true)
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange(FieldRule) && newRules.(*schema.Set).Len() > 0 {
+		return d.SetNewComputed(FieldRules)
+	}
+
+	return nil
+}
+
+// Reads a policy either by its ID or by its name, returns nil if the policy doesn't exist
+func readPolicy(client *consul.Client, policyRef string) (*consul.ACLPolicy, error) {
+	id, name := parseLinkReference(policyRef)
+
+	// There is no API to read a policy by its name
+	if id == "" {
+		policies, _, err := client.ACL().PolicyList(nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ACL policies: %w", classifyACLError(client, err))
+		}
+		for _, entry := range policies {
+			if entry.Name == name {
+				id = entry.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, nil
+		}
+	}
+
+	policy, _, err := client.ACL().PolicyRead(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading ACL policy %q: %w", policyRef, err)
+	}
+
+	return policy, nil
+}
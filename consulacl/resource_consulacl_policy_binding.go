@@ -4,6 +4,7 @@ import (
 	"fmt"
 	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
 )
 
 func resourceConsulAclPolicyBinding() *schema.Resource {
@@ -11,6 +12,9 @@ func resourceConsulAclPolicyBinding() *schema.Resource {
 		Create: resourceConsulAclPolicyBindingCreate,
 		Read:   resourceConsulAclPolicyBindingRead,
 		Delete: resourceConsulAclPolicyBindingDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulAclPolicyBindingImport,
+		},
 
 		Schema: map[string]*schema.Schema{
 			FieldAccessor: {
@@ -25,67 +29,59 @@ func resourceConsulAclPolicyBinding() *schema.Resource {
 				ForceNew:    true,
 				Description: "Policy name",
 			},
+			FieldDatacenter: datacenterSchema(),
 		},
 	}
 }
 
 func resourceConsulAclPolicyBindingCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
-
-	accessor := d.Get(FieldAccessor).(string)
-	policy := d.Get(FieldPolicy).(string)
-
-	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
+	client, err := getClient(d, meta)
 	if err != nil {
 		return err
 	}
 
-	d.SetId(getSHA256(accessor + policy))
+	accessor := d.Get(FieldAccessor).(string)
+	policy := d.Get(FieldPolicy).(string)
 
-	index := -1
-	for i, policyLink := range aclToken.Policies {
-		if policyLink.Name == policy {
-			index = i
-			break
+	err = mutateToken(client, accessor, func(aclToken *consul.ACLToken) bool {
+		if findTokenPolicyLink(aclToken, policy) >= 0 {
+			return false
 		}
-	}
 
-	if index >= 0 {
-		return nil
-	}
-
-	aclToken.Policies = append(aclToken.Policies, &consul.ACLTokenPolicyLink{
-		Name: policy,
+		aclToken.Policies = append(aclToken.Policies, &consul.ACLTokenPolicyLink{
+			Name: policy,
+		})
+		return true
 	})
-
-	_, _, err = client.ACL().TokenUpdate(aclToken, nil)
 	if err != nil {
 		return fmt.Errorf("error binding ACL token %q to the policy %q: %s", accessor, policy, err)
 	}
 
+	d.SetId(getSHA256(accessor + policy))
+
 	return nil
 }
 
 func resourceConsulAclPolicyBindingRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	accessor := d.Get(FieldAccessor).(string)
 	policy := d.Get(FieldPolicy).(string)
 
 	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
 	if err != nil {
-		return err
-	}
-
-	index := -1
-	for i, policyLink := range aclToken.Policies {
-		if policyLink.Name == policy {
-			index = i
-			break
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			d.SetId("") // token not found so the binding is gone as well
+			return nil
 		}
+		return fmt.Errorf("error reading ACL token %q: %s", accessor, err)
 	}
 
-	if index < 0 {
+	if findTokenPolicyLink(aclToken, policy) < 0 {
 		d.SetId("")
 	}
 
@@ -93,34 +89,69 @@ func resourceConsulAclPolicyBindingRead(d *schema.ResourceData, meta interface{}
 }
 
 func resourceConsulAclPolicyBindingDelete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	accessor := d.Get(FieldAccessor).(string)
 	policy := d.Get(FieldPolicy).(string)
 
-	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
-	if err != nil {
+	err = mutateToken(client, accessor, func(aclToken *consul.ACLToken) bool {
+		index := findTokenPolicyLink(aclToken, policy)
+		if index < 0 {
+			return false // already not present
+		}
+
+		// that's how you delete an element from a slice in go T_T
+		aclToken.Policies = append(aclToken.Policies[:index], aclToken.Policies[index+1:]...)
+		return true
+	})
+	if isNotFoundError(err) {
 		return nil // token not found but it also means there are no bindings
 	}
+	if err != nil {
+		return fmt.Errorf("error un-binding ACL token %q from the policy %q: %s", accessor, policy, err)
+	}
 
-	index := -1
-	for i, policyLink := range aclToken.Policies {
-		if policyLink.Name == policy {
-			index = i
-			break
-		}
+	return nil
+}
+
+// Policy bindings are imported by IDs in the form of `<accessor>:<policy>`
+func resourceConsulAclPolicyBindingImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID %q, expected '<accessor>:<policy>'", d.Id())
 	}
 
-	if index < 0 {
-		return nil // already not present
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
 	}
 
-	// that's how you delete an element from a slice in go T_T
-	aclToken.Policies = append(aclToken.Policies[:index], aclToken.Policies[index+1:]...)
-	_, _, err = client.ACL().TokenUpdate(aclToken, nil)
+	accessor, policy := parts[0], parts[1]
+
+	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
 	if err != nil {
-		return fmt.Errorf("error un-binding ACL token %q from the policy %q: %s", accessor, policy, err)
+		return nil, fmt.Errorf("error reading ACL token %q: %s", accessor, classifyACLError(client, err))
 	}
 
-	return nil
+	if findTokenPolicyLink(aclToken, policy) < 0 {
+		return nil, fmt.Errorf("ACL token %q is not bound to the policy %q", accessor, policy)
+	}
+
+	_ = d.Set(FieldAccessor, accessor)
+	_ = d.Set(FieldPolicy, policy)
+	d.SetId(getSHA256(accessor + policy))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func findTokenPolicyLink(aclToken *consul.ACLToken, policy string) int {
+	for i, policyLink := range aclToken.Policies {
+		if policyLink.Name == policy {
+			return i
+		}
+	}
+	return -1
 }
@@ -3,12 +3,14 @@ package consulacl_test
 import (
 	"fmt"
 	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/terraform"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
 	"os"
+	"strings"
 )
 
 const resourcePolicyBinding = `
@@ -20,7 +22,7 @@ resource "consulacl_policy_binding" "test" {
 
 func TestIntegrationPolicyBinding(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		IsUnitTest: false,
+		IsUnitTest: testAgainstFake,
 		Providers:  testProviders,
 		PreCheck:   func() { testResourcePolicyBindingPreConfig(t) },
 		Steps: []resource.TestStep{
@@ -30,6 +32,12 @@ func TestIntegrationPolicyBinding(t *testing.T) {
 					testResourcePolicyBinding("00000000-0000-0000-0000-000000000002", "global-management", true),
 				),
 			},
+			{
+				ResourceName:      "consulacl_policy_binding.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     "00000000-0000-0000-0000-000000000002:global-management",
+			},
 			{
 				Config: "locals {}",
 				Check: resource.ComposeTestCheckFunc(
@@ -97,3 +105,85 @@ func testResourcePolicyBinding(accessor, policy string, expected bool) resource.
 		return nil
 	}
 }
+
+const resourcePolicyBindingConcurrentAccessor = "2d8c1f9e-7a6b-4c5d-8e9f-0a1b2c3d4e5f"
+const resourcePolicyBindingConcurrentCount = 10
+
+const resourcePolicyBindingConcurrent = `
+resource "consulacl_policy_binding" "test" {
+  count = 10
+  accessor = "2d8c1f9e-7a6b-4c5d-8e9f-0a1b2c3d4e5f"
+  policy = "test-concurrent-binding-${count.index}"
+}
+`
+
+func TestIntegrationPolicyBindingConcurrent(t *testing.T) {
+	var checks []resource.TestCheckFunc
+	var absentChecks []resource.TestCheckFunc
+	for i := 0; i < resourcePolicyBindingConcurrentCount; i++ {
+		policy := fmt.Sprintf("test-concurrent-binding-%d", i)
+		checks = append(checks, testResourcePolicyBinding(resourcePolicyBindingConcurrentAccessor, policy, true))
+		absentChecks = append(absentChecks, testResourcePolicyBinding(resourcePolicyBindingConcurrentAccessor, policy, false))
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePolicyBindingConcurrentPreConfig(t) },
+		CheckDestroy: testResourcePolicyBindingConcurrentDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: resourcePolicyBindingConcurrent,
+				Check:  resource.ComposeTestCheckFunc(checks...),
+			},
+			{
+				Config: "locals {}",
+				Check:  resource.ComposeTestCheckFunc(absentChecks...),
+			},
+		},
+	})
+}
+
+func testResourcePolicyBindingConcurrentPreConfig(t *testing.T) {
+	testResourcePreConfig(t)
+
+	for i := 0; i < resourcePolicyBindingConcurrentCount; i++ {
+		policy := &consul.ACLPolicy{
+			Name:  fmt.Sprintf("test-concurrent-binding-%d", i),
+			Rules: `node_prefix "" { policy = "read" }`,
+		}
+		if _, _, err := testClient.ACL().PolicyCreate(policy, nil); err != nil {
+			t.Fatal("Cannot provision a test policy for consulacl_policy_binding resource test", err)
+		}
+	}
+
+	token := &consul.ACLToken{
+		AccessorID:  resourcePolicyBindingConcurrentAccessor,
+		Description: "Test Concurrent Policy Bindings",
+	}
+
+	if _, _, err := testClient.ACL().TokenCreate(token, nil); err != nil {
+		t.Fatal("Cannot provision a test token for consulacl_policy_binding resource test", err)
+	}
+}
+
+func testResourcePolicyBindingConcurrentDestroy(s *terraform.State) error {
+	if _, err := testClient.ACL().TokenDelete(resourcePolicyBindingConcurrentAccessor, nil); err != nil {
+		return err
+	}
+
+	policies, _, err := testClient.ACL().PolicyList(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if strings.HasPrefix(policy.Name, "test-concurrent-binding-") {
+			if _, err := testClient.ACL().PolicyDelete(policy.ID, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
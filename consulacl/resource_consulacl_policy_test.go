@@ -0,0 +1,122 @@
+package consulacl_test
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"testing"
+)
+
+const resourceAclPolicyConfigRules = `
+resource "consulacl_policy" "test" {
+	name = "test-policy"
+	description = "Test Policy Initial"
+	rules = "node_prefix \"\" { policy = \"read\" }"
+}
+`
+
+// Same rules as above, but in JSON
+const resourceAclPolicyConfigRulesJSON = `
+resource "consulacl_policy" "test" {
+	name = "test-policy"
+	description = "Test Policy Initial"
+	rules = <<EOF
+{
+	"node_prefix": {
+		"": {"policy": "read"}
+	}
+}
+EOF
+}
+`
+
+const resourceAclPolicyConfigRule = `
+resource "consulacl_policy" "test" {
+	name = "test-policy"
+	description = "Test Policy Updated"
+	datacenters = ["dc1"]
+
+	rule {
+		scope = "key"
+		prefix = "Foo/Bar"
+		policy = "write"
+	}
+	rule {
+		scope = "operator"
+		policy = "read"
+	}
+	rule {
+		scope = "acl"
+		policy = "read"
+	}
+	rule {
+		scope = "service"
+		prefix = ""
+		policy = "read"
+		intentions = "write"
+	}
+}
+`
+
+const resourceAclPolicyRulesUpdated = `acl = "read"
+key_prefix "Foo/Bar" { policy = "write" }
+operator = "read"
+service_prefix "" { policy = "read", intentions = "write" }
+`
+
+func TestIntegrationResourcePolicy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourcePolicyAbsent("test-policy"),
+		Steps: []resource.TestStep{
+			{
+				Config: resourceAclPolicyConfigRules,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldName, "test-policy"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldDescription, "Test Policy Initial"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldRules, "node_prefix \"\" { policy = \"read\" }\n"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", "rule.#", "0"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", "datacenters.#", "0"),
+				),
+			},
+			{
+				Config:   resourceAclPolicyConfigRulesJSON,
+				PlanOnly: true,
+			},
+			{
+				Config: resourceAclPolicyConfigRule,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldName, "test-policy"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldDescription, "Test Policy Updated"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", consulacl.FieldRules, resourceAclPolicyRulesUpdated),
+					resource.TestCheckResourceAttr("consulacl_policy.test", "rule.#", "4"),
+					resource.TestCheckResourceAttr("consulacl_policy.test", "datacenters.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "consulacl_policy.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"rule"},
+			},
+		},
+	})
+}
+
+func testResourcePolicyAbsent(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		policies, _, err := testClient.ACL().PolicyList(nil)
+		if err != nil {
+			return fmt.Errorf("error listing ACL policies in Consul: %s", err)
+		}
+		for _, entry := range policies {
+			if entry.Name == name {
+				return fmt.Errorf("a test policy %q wasn't deleted from Consul", name)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,332 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"regexp"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func resourceConsulAclRole() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclRoleCreate,
+		Read:   resourceConsulAclRoleRead,
+		Update: resourceConsulAclRoleUpdate,
+		Delete: resourceConsulAclRoleDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulAclRoleImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldName: {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			FieldDescription: {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			FieldPolicies: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Policy names or IDs",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			FieldPoliciesAuthoritative: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether policies not declared in the configuration should be removed from the role",
+			},
+			FieldServiceIdentity:    serviceIdentitySchema(),
+			FieldWaitForReplication: waitForReplicationSchema("Datacenters to wait for the role to be replicated to, overrides the provider's default"),
+			FieldDatacenter:         datacenterSchema(),
+		},
+	}
+}
+
+func serviceIdentitySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				FieldServiceName: {
+					Type:     schema.TypeString,
+					Required: true,
+				},
+				FieldDatacenters: {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceConsulAclRoleCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	role := getRole(d)
+
+	created, _, err := client.ACL().RoleCreate(role, nil)
+	if err != nil {
+		return fmt.Errorf("error creating ACL role %q: %s", role.Name, classifyACLError(client, err))
+	}
+
+	d.SetId(created.ID)
+
+	if err = waitForRoleReplication(d, meta); err != nil {
+		return err
+	}
+
+	return resourceConsulAclRoleRead(d, meta)
+}
+
+func resourceConsulAclRoleRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	role, _, err := client.ACL().RoleRead(id, nil)
+	if err != nil {
+		return fmt.Errorf("error reading ACL role %q: %s", id, classifyACLError(client, err))
+	}
+
+	if role == nil {
+		d.SetId("")
+		return nil
+	}
+
+	if err = d.Set(FieldName, role.Name); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldName, err)
+	}
+
+	if err = d.Set(FieldDescription, role.Description); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
+	}
+
+	authoritative := d.Get(FieldPoliciesAuthoritative).(bool)
+	configured := d.Get(FieldPolicies).(*schema.Set)
+	policies := make([]string, 0, len(role.Policies))
+	for _, policyLink := range role.Policies {
+		// policies bound by other resources are only tracked by roles owning the complete set
+		if authoritative || isLinkDeclared(configured, policyLink.ID, policyLink.Name) {
+			policies = append(policies, linkReference(configured, policyLink.ID, policyLink.Name))
+		}
+	}
+
+	if err = d.Set(FieldPolicies, policies); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
+	}
+
+	if err = d.Set(FieldServiceIdentity, flattenServiceIdentities(role.ServiceIdentities)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldServiceIdentity, err)
+	}
+
+	return nil
+}
+
+func resourceConsulAclRoleUpdate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	// Policies might be bound to the role by other resources so the update has to be serialized with them
+	err = mutateRole(client, id, func(role *consul.ACLRole) bool {
+		declared := getRole(d)
+		policies := updateRolePolicyLinks(d, role.Policies)
+
+		if role.Name == declared.Name && role.Description == declared.Description &&
+			sameLinks(rolePolicyObjectLinks(role.Policies), rolePolicyObjectLinks(policies)) &&
+			sameServiceIdentities(role.ServiceIdentities, declared.ServiceIdentities) {
+			return false
+		}
+
+		role.Name = declared.Name
+		role.Description = declared.Description
+		role.Policies = policies
+		role.ServiceIdentities = declared.ServiceIdentities
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error updating ACL role %q: %s", id, err)
+	}
+
+	if err = waitForRoleReplication(d, meta); err != nil {
+		return err
+	}
+
+	return resourceConsulAclRoleRead(d, meta)
+}
+
+func waitForRoleReplication(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	wait, err := getReplicationWait(d, meta.(*Meta))
+	if err != nil {
+		return err
+	}
+
+	return waitForReplication(client, wait, "ACL role", false, func(q *consul.QueryOptions) ([]byte, error) {
+		role, _, err := client.ACL().RoleRead(d.Id(), q)
+		if err != nil {
+			return nil, classifyACLError(client, err)
+		}
+		if role == nil {
+			return nil, nil
+		}
+		return role.Hash, nil
+	})
+}
+
+func resourceConsulAclRoleDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	_, err = client.ACL().RoleDelete(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("error deleting ACL role %q: %s", id, err)
+	}
+
+	return nil
+}
+
+// Roles can be imported either by ID or by name
+func resourceConsulAclRoleImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	// defaults are not applied on import so an imported role would otherwise be non-authoritative until the next apply
+	if err := d.Set(FieldPoliciesAuthoritative, true); err != nil {
+		return nil, fmt.Errorf("error while setting %q: %s", FieldPoliciesAuthoritative, err)
+	}
+
+	if uuidRegexp.MatchString(d.Id()) {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	role, _, err := client.ACL().RoleReadByName(d.Id(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ACL role %q: %s", d.Id(), classifyACLError(client, err))
+	}
+
+	if role == nil {
+		return nil, fmt.Errorf("ACL role %q not found", d.Id())
+	}
+
+	d.SetId(role.ID)
+	return []*schema.ResourceData{d}, nil
+}
+
+func getRole(d *schema.ResourceData) *consul.ACLRole {
+	role := &consul.ACLRole{
+		Name:              d.Get(FieldName).(string),
+		Description:       d.Get(FieldDescription).(string),
+		ServiceIdentities: expandServiceIdentities(d.Get(FieldServiceIdentity).(*schema.Set).List()),
+	}
+
+	for _, raw := range d.Get(FieldPolicies).(*schema.Set).List() {
+		id, name := parseLinkReference(raw.(string))
+		role.Policies = append(role.Policies, &consul.ACLRolePolicyLink{ID: id, Name: name})
+	}
+
+	return role
+}
+
+// Same as updateToken14PolicyLinks but for policies linked to roles
+func updateRolePolicyLinks(d *schema.ResourceData, current []*consul.ACLRolePolicyLink) []*consul.ACLRolePolicyLink {
+	oldPolicies, newPolicies := d.GetChange(FieldPolicies)
+	previous := oldPolicies.(*schema.Set)
+	declared := newPolicies.(*schema.Set)
+
+	var result []*consul.ACLRolePolicyLink
+
+	if !d.Get(FieldPoliciesAuthoritative).(bool) {
+		for _, policyLink := range current {
+			if !isLinkDeclared(previous, policyLink.ID, policyLink.Name) && !isLinkDeclared(declared, policyLink.ID, policyLink.Name) {
+				result = append(result, policyLink)
+			}
+		}
+	}
+
+	return append(result, getRole(d).Policies...)
+}
+
+// Links to policies and roles can be defined either by IDs or by names so we have to tell them apart
+func parseLinkReference(reference string) (id string, name string) {
+	if uuidRegexp.MatchString(reference) {
+		return reference, ""
+	}
+	return "", reference
+}
+
+// Preserve whichever form of a link reference was used in the configuration to avoid spurious diffs
+func linkReference(configured *schema.Set, id, name string) string {
+	if configured.Contains(id) {
+		return id
+	}
+	return name
+}
+
+// Whether the link is referenced in the configuration either by its ID or by its name
+func isLinkDeclared(configured *schema.Set, id, name string) bool {
+	return configured.Contains(id) || configured.Contains(name)
+}
+
+func expandServiceIdentities(rawIdentities []interface{}) []*consul.ACLServiceIdentity {
+	var result []*consul.ACLServiceIdentity
+	for _, raw := range rawIdentities {
+		definition := raw.(map[string]interface{})
+
+		identity := &consul.ACLServiceIdentity{
+			ServiceName: definition[FieldServiceName].(string),
+		}
+		for _, dc := range definition[FieldDatacenters].(*schema.Set).List() {
+			identity.Datacenters = append(identity.Datacenters, dc.(string))
+		}
+
+		result = append(result, identity)
+	}
+	return result
+}
+
+func flattenServiceIdentities(identities []*consul.ACLServiceIdentity) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, map[string]interface{}{
+			FieldServiceName: identity.ServiceName,
+			FieldDatacenters: identity.Datacenters,
+		})
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
+)
+
+func resourceConsulAclRoleBinding() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclRoleBindingCreate,
+		Read:   resourceConsulAclRoleBindingRead,
+		Delete: resourceConsulAclRoleBindingDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulAclRoleBindingImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldAccessor: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Token's accessor ID",
+			},
+			FieldRole: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Role name or ID",
+			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclRoleBindingCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	accessor := d.Get(FieldAccessor).(string)
+	role := d.Get(FieldRole).(string)
+
+	err = mutateToken(client, accessor, func(aclToken *consul.ACLToken) bool {
+		if findTokenRoleLink(aclToken, role) >= 0 {
+			return false
+		}
+
+		id, name := parseLinkReference(role)
+		aclToken.Roles = append(aclToken.Roles, &consul.ACLTokenRoleLink{
+			ID:   id,
+			Name: name,
+		})
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error binding ACL token %q to the role %q: %s", accessor, role, err)
+	}
+
+	d.SetId(getSHA256(accessor + role))
+
+	return nil
+}
+
+func resourceConsulAclRoleBindingRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	accessor := d.Get(FieldAccessor).(string)
+	role := d.Get(FieldRole).(string)
+
+	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			d.SetId("") // token not found so the binding is gone as well
+			return nil
+		}
+		return fmt.Errorf("error reading ACL token %q: %s", accessor, err)
+	}
+
+	if findTokenRoleLink(aclToken, role) < 0 {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourceConsulAclRoleBindingDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	accessor := d.Get(FieldAccessor).(string)
+	role := d.Get(FieldRole).(string)
+
+	err = mutateToken(client, accessor, func(aclToken *consul.ACLToken) bool {
+		index := findTokenRoleLink(aclToken, role)
+		if index < 0 {
+			return false // already not present
+		}
+
+		aclToken.Roles = append(aclToken.Roles[:index], aclToken.Roles[index+1:]...)
+		return true
+	})
+	if isNotFoundError(err) {
+		return nil // token not found but it also means there are no bindings
+	}
+	if err != nil {
+		return fmt.Errorf("error un-binding ACL token %q from the role %q: %s", accessor, role, err)
+	}
+
+	return nil
+}
+
+// Role bindings are imported by IDs in the form of `<accessor>:<role>`
+func resourceConsulAclRoleBindingImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID %q, expected '<accessor>:<role>'", d.Id())
+	}
+
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, role := parts[0], parts[1]
+
+	aclToken, _, err := client.ACL().TokenRead(accessor, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ACL token %q: %s", accessor, classifyACLError(client, err))
+	}
+
+	if findTokenRoleLink(aclToken, role) < 0 {
+		return nil, fmt.Errorf("ACL token %q is not bound to the role %q", accessor, role)
+	}
+
+	_ = d.Set(FieldAccessor, accessor)
+	_ = d.Set(FieldRole, role)
+	d.SetId(getSHA256(accessor + role))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+func findTokenRoleLink(aclToken *consul.ACLToken, role string) int {
+	for i, roleLink := range aclToken.Roles {
+		if roleLink.Name == role || roleLink.ID == role {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,104 @@
+package consulacl_test
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"testing"
+)
+
+const resourceRoleBindingAccessor = "5a3f3b1c-4b8e-4f0a-9d6c-2e7f8a9b0c1d"
+
+const resourceRoleBinding = `
+resource "consulacl_role" "test" {
+  name = "test-role-binding"
+}
+
+resource "consulacl_role_binding" "test" {
+  accessor = "5a3f3b1c-4b8e-4f0a-9d6c-2e7f8a9b0c1d"
+  role = "${consulacl_role.test.name}"
+}
+`
+
+const resourceRoleBindingRemoved = `
+resource "consulacl_role" "test" {
+  name = "test-role-binding"
+}
+`
+
+func TestIntegrationRoleBinding(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourceRoleBindingPreConfig(t) },
+		CheckDestroy: func(s *terraform.State) error {
+			_, err := testClient.ACL().TokenDelete(resourceRoleBindingAccessor, nil)
+			return err
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: resourceRoleBinding,
+				Check: resource.ComposeTestCheckFunc(
+					testResourceRoleBinding(resourceRoleBindingAccessor, "test-role-binding", true),
+				),
+			},
+			{
+				ResourceName:      "consulacl_role_binding.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     resourceRoleBindingAccessor + ":test-role-binding",
+			},
+			{
+				Config: resourceRoleBindingRemoved,
+				Check: resource.ComposeTestCheckFunc(
+					testResourceRoleBinding(resourceRoleBindingAccessor, "test-role-binding", false),
+				),
+			},
+		},
+	})
+}
+
+func testResourceRoleBindingPreConfig(t *testing.T) {
+	testResourcePreConfig(t)
+
+	token := &consul.ACLToken{
+		AccessorID:  resourceRoleBindingAccessor,
+		Description: "Test Role Binding",
+	}
+
+	_, _, err := testClient.ACL().TokenCreate(token, nil)
+	if err != nil {
+		t.Fatal("Cannot provision a test token for consulacl_role_binding resource test", err)
+	}
+}
+
+func testResourceRoleBinding(accessor, role string, expected bool) resource.TestCheckFunc {
+	states := map[bool]string{true: "present", false: "absent"}
+	return func(s *terraform.State) error {
+		aclToken, _, err := testClient.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, roleLink := range aclToken.Roles {
+			if roleLink.Name == role {
+				found = true
+				break
+			}
+		}
+
+		if found != expected {
+			return fmt.Errorf(
+				"A binding between token %q and role %s was expected to be %s but was %s",
+				accessor,
+				role,
+				states[expected],
+				states[found],
+			)
+		}
+
+		return nil
+	}
+}
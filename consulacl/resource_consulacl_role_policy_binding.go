@@ -0,0 +1,175 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+	"strings"
+)
+
+func resourceConsulAclRolePolicyBinding() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclRolePolicyBindingCreate,
+		Read:   resourceConsulAclRolePolicyBindingRead,
+		Delete: resourceConsulAclRolePolicyBindingDelete,
+		Importer: &schema.ResourceImporter{
+			State: resourceConsulAclRolePolicyBindingImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldRole: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Role name or ID",
+			},
+			FieldPolicy: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Policy name or ID",
+			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclRolePolicyBindingCreate(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	roleRef := d.Get(FieldRole).(string)
+	policy := d.Get(FieldPolicy).(string)
+
+	err = mutateRole(client, roleRef, func(role *consul.ACLRole) bool {
+		if findRolePolicyLink(role, policy) >= 0 {
+			return false
+		}
+
+		id, name := parseLinkReference(policy)
+		role.Policies = append(role.Policies, &consul.ACLRolePolicyLink{
+			ID:   id,
+			Name: name,
+		})
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error binding ACL role %q to the policy %q: %s", roleRef, policy, err)
+	}
+
+	d.SetId(getSHA256(roleRef + policy))
+
+	return nil
+}
+
+func resourceConsulAclRolePolicyBindingRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	roleRef := d.Get(FieldRole).(string)
+	policy := d.Get(FieldPolicy).(string)
+
+	role, err := readRole(client, roleRef)
+	if err != nil {
+		return err
+	}
+
+	if role == nil || findRolePolicyLink(role, policy) < 0 {
+		d.SetId("")
+	}
+
+	return nil
+}
+
+func resourceConsulAclRolePolicyBindingDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	roleRef := d.Get(FieldRole).(string)
+	policy := d.Get(FieldPolicy).(string)
+
+	err = mutateRole(client, roleRef, func(role *consul.ACLRole) bool {
+		index := findRolePolicyLink(role, policy)
+		if index < 0 {
+			return false // already not present
+		}
+
+		role.Policies = append(role.Policies[:index], role.Policies[index+1:]...)
+		return true
+	})
+	if isNotFoundError(err) {
+		return nil // role not found but it also means there are no bindings
+	}
+	if err != nil {
+		return fmt.Errorf("error un-binding ACL role %q from the policy %q: %s", roleRef, policy, err)
+	}
+
+	return nil
+}
+
+// Role policy bindings are imported by IDs in the form of `<role>:<policy>`
+func resourceConsulAclRolePolicyBindingImport(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID %q, expected '<role>:<policy>'", d.Id())
+	}
+
+	client, err := getClient(d, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	roleRef, policy := parts[0], parts[1]
+
+	role, err := readRole(client, roleRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		return nil, fmt.Errorf("ACL role %q not found", roleRef)
+	}
+
+	if findRolePolicyLink(role, policy) < 0 {
+		return nil, fmt.Errorf("ACL role %q is not bound to the policy %q", roleRef, policy)
+	}
+
+	_ = d.Set(FieldRole, roleRef)
+	_ = d.Set(FieldPolicy, policy)
+	d.SetId(getSHA256(roleRef + policy))
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// Reads a role either by its ID or by its name, returns nil if the role doesn't exist
+func readRole(client *consul.Client, roleRef string) (*consul.ACLRole, error) {
+	var role *consul.ACLRole
+	var err error
+
+	if uuidRegexp.MatchString(roleRef) {
+		role, _, err = client.ACL().RoleRead(roleRef, nil)
+	} else {
+		role, _, err = client.ACL().RoleReadByName(roleRef, nil)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error reading ACL role %q: %w", roleRef, classifyACLError(client, err))
+	}
+
+	return role, nil
+}
+
+func findRolePolicyLink(role *consul.ACLRole, policy string) int {
+	for i, policyLink := range role.Policies {
+		if policyLink.Name == policy || policyLink.ID == policy {
+			return i
+		}
+	}
+	return -1
+}
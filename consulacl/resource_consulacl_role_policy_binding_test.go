@@ -0,0 +1,107 @@
+package consulacl_test
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"testing"
+)
+
+const resourceRolePolicyBindingRole = "test-role-policy-binding"
+
+const resourceRolePolicyBinding = `
+resource "consulacl_role_policy_binding" "test" {
+  role = "test-role-policy-binding"
+  policy = "global-management"
+}
+`
+
+func TestIntegrationRolePolicyBinding(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourceRolePolicyBindingPreConfig(t) },
+		CheckDestroy: testResourceRolePolicyBindingDestroy(resourceRolePolicyBindingRole),
+		Steps: []resource.TestStep{
+			{
+				Config: resourceRolePolicyBinding,
+				Check: resource.ComposeTestCheckFunc(
+					testResourceRolePolicyBinding(resourceRolePolicyBindingRole, "global-management", true),
+				),
+			},
+			{
+				ResourceName:      "consulacl_role_policy_binding.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     resourceRolePolicyBindingRole + ":global-management",
+			},
+			{
+				Config: "locals {}",
+				Check: resource.ComposeTestCheckFunc(
+					testResourceRolePolicyBinding(resourceRolePolicyBindingRole, "global-management", false),
+				),
+			},
+		},
+	})
+}
+
+func testResourceRolePolicyBindingPreConfig(t *testing.T) {
+	testResourcePreConfig(t)
+
+	role := &consul.ACLRole{
+		Name: resourceRolePolicyBindingRole,
+	}
+
+	_, _, err := testClient.ACL().RoleCreate(role, nil)
+	if err != nil {
+		t.Fatal("Cannot provision a test role for consulacl_role_policy_binding resource test", err)
+	}
+}
+
+func testResourceRolePolicyBindingDestroy(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		role, _, err := testClient.ACL().RoleReadByName(name, nil)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			return fmt.Errorf("a test role %q doesn't exist but should", name)
+		}
+		_, err = testClient.ACL().RoleDelete(role.ID, nil)
+		return err
+	}
+}
+
+func testResourceRolePolicyBinding(roleName, policy string, expected bool) resource.TestCheckFunc {
+	states := map[bool]string{true: "present", false: "absent"}
+	return func(s *terraform.State) error {
+		role, _, err := testClient.ACL().RoleReadByName(roleName, nil)
+		if err != nil {
+			return err
+		}
+		if role == nil {
+			return fmt.Errorf("a test role %q doesn't exist but should", roleName)
+		}
+
+		found := false
+		for _, policyLink := range role.Policies {
+			if policyLink.Name == policy {
+				found = true
+				break
+			}
+		}
+
+		if found != expected {
+			return fmt.Errorf(
+				"A binding between role %q and policy %s was expected to be %s but was %s",
+				roleName,
+				policy,
+				states[expected],
+				states[found],
+			)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package consulacl_test
+
+import (
+	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"testing"
+)
+
+const resourceAclRoleConfigInitial = `
+resource "consulacl_role" "test" {
+	name = "test-role"
+	description = "Test Role Initial"
+	policies = ["global-management"]
+}
+`
+
+const resourceAclRoleConfigUpdated = `
+resource "consulacl_role" "test" {
+	name = "test-role"
+	description = "Test Role Updated"
+
+	service_identity {
+		service_name = "web"
+		datacenters = ["dc1"]
+	}
+}
+`
+
+func TestIntegrationResourceRole(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceRoleAbsent("test-role"),
+		Steps: []resource.TestStep{
+			{
+				Config: resourceAclRoleConfigInitial,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_role.test", consulacl.FieldName, "test-role"),
+					resource.TestCheckResourceAttr("consulacl_role.test", consulacl.FieldDescription, "Test Role Initial"),
+					resource.TestCheckResourceAttr("consulacl_role.test", "policies.#", "1"),
+					// 'policies' is a set and '938696404' appears to be a hash-value of the string 'global-management' ¯\_(ツ)_/¯
+					resource.TestCheckResourceAttr("consulacl_role.test", "policies.938696404", "global-management"),
+					resource.TestCheckResourceAttr("consulacl_role.test", "service_identity.#", "0"),
+				),
+			},
+			{
+				Config: resourceAclRoleConfigUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_role.test", consulacl.FieldDescription, "Test Role Updated"),
+					resource.TestCheckResourceAttr("consulacl_role.test", "policies.#", "0"),
+					resource.TestCheckResourceAttr("consulacl_role.test", "service_identity.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "consulacl_role.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "consulacl_role.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     "test-role",
+			},
+		},
+	})
+}
+
+func testResourceRoleAbsent(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		role, _, err := testClient.ACL().RoleReadByName(name, nil)
+		if err != nil {
+			return fmt.Errorf("error reading ACL role %q from Consul: %s", name, err)
+		}
+		if role != nil {
+			return fmt.Errorf("a test role %q wasn't deleted from Consul", name)
+		}
+		return nil
+	}
+}
+
+const resourceAclRoleConfigNonAuthoritative = `
+resource "consulacl_policy" "bound" {
+	name = "test-role-bound"
+	rules = "node_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulacl_role" "shared" {
+	name = "test-role-shared"
+	policies = ["global-management"]
+	policies_authoritative = false
+}
+
+resource "consulacl_role_policy_binding" "bound" {
+	role = "${consulacl_role.shared.name}"
+	policy = "${consulacl_policy.bound.name}"
+}
+`
+
+const resourceAclRoleConfigNonAuthoritativeEmpty = `
+resource "consulacl_policy" "bound" {
+	name = "test-role-bound"
+	rules = "node_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulacl_role" "shared" {
+	name = "test-role-shared"
+	description = "Test Shared Role"
+	policies_authoritative = false
+}
+
+resource "consulacl_role_policy_binding" "bound" {
+	role = "${consulacl_role.shared.name}"
+	policy = "${consulacl_policy.bound.name}"
+}
+`
+
+func TestIntegrationResourceRoleNonAuthoritative(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceRoleAbsent("test-role-shared"),
+		Steps: []resource.TestStep{
+			{
+				// the policy bound by another resource must not cause a perpetual diff
+				Config: resourceAclRoleConfigNonAuthoritative,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_role.shared", "policies.#", "1"),
+					testResourceRolePolicyBinding("test-role-shared", "global-management", true),
+					testResourceRolePolicyBinding("test-role-shared", "test-role-bound", true),
+				),
+			},
+			{
+				// removing all declared policies must really remove them but keep the bound one
+				Config: resourceAclRoleConfigNonAuthoritativeEmpty,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_role.shared", consulacl.FieldDescription, "Test Shared Role"),
+					resource.TestCheckResourceAttr("consulacl_role.shared", "policies.#", "0"),
+					testResourceRolePolicyBinding("test-role-shared", "global-management", false),
+					testResourceRolePolicyBinding("test-role-shared", "test-role-bound", true),
+				),
+			},
+		},
+	})
+}
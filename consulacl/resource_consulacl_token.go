@@ -3,25 +3,35 @@ package consulacl
 import (
 	"crypto/sha256"
 	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
 	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/go-multierror"
-	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
-	"reflect"
-	"sort"
 	"strings"
 )
 
-var prefixedScopes = []string{"agent", "event", "key", "node", "query", "service", "session"}
-var singletonScopes = []string{"keyring", "operator"}
+var prefixedScopes = rules.PrefixedResources
+
+// Post-1.4 ACL policies support a few more scopes than legacy tokens
+var policySingletonScopes = rules.SingletonResources
+var singletonScopes = legacySingletonScopes(policySingletonScopes)
+
+func legacySingletonScopes(scopes []string) []string {
+	var result []string
+	for _, scope := range scopes {
+		if !stringInSlice(scope, []string{"acl", "mesh", "peering"}) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
 
 const anonymousToken = "anonymous"
 
 func resourceConsulAclToken() *schema.Resource {
-	var allScopes []string
-	allScopes = append(allScopes, prefixedScopes...)
-	allScopes = append(allScopes, singletonScopes...)
+	rule := ruleSchema(singletonScopes)
+	rule.ConflictsWith = []string{FieldRules}
 
 	return &schema.Resource{
 		Create: resourceConsulAclTokenCreate,
@@ -45,33 +55,9 @@ func resourceConsulAclToken() *schema.Resource {
 				Required: true,
 			},
 
-			FieldRule: {
-				Type:     schema.TypeSet,
-				Optional: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						FieldScope: {
-							Type: schema.TypeString,
-							// it's required but we have to enforce otherwise due to a bug in terraform
-							// when injecting rules as
-							// rule = ["${data.null_data_source.policy.*.outputs}"]
-							Optional:     true,
-							ValidateFunc: validation.StringInSlice(allScopes, true),
-						},
-						FieldPrefix: {
-							Type:     schema.TypeString,
-							Optional: true,
-						},
-						FieldPolicy: {
-							Type: schema.TypeString,
-							// it's required but we have to enforce otherwise due to a bug in terraform
-							// when injecting rules as
-							// rule = ["${data.null_data_source.policy.*.outputs}"]
-							Optional: true,
-						},
-					},
-				},
-			},
+			FieldRule: rule,
+
+			FieldRules: rulesSchema(),
 
 			FieldToken: {
 				Type:      schema.TypeString,
@@ -85,14 +71,115 @@ func resourceConsulAclToken() *schema.Resource {
 				Required:     true,
 				ValidateFunc: validation.StringInSlice([]string{"client", "management"}, true),
 			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+// Legacy tokens and post-1.4 policies differ in the scopes that cannot be scoped to a name
+func ruleSchema(singletons []string) *schema.Schema {
+	var allScopes []string
+	allScopes = append(allScopes, prefixedScopes...)
+	allScopes = append(allScopes, singletons...)
+
+	return &schema.Schema{
+		Type:     schema.TypeSet,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				FieldScope: {
+					Type: schema.TypeString,
+					// it's required but we have to enforce otherwise due to a bug in terraform
+					// when injecting rules as
+					// rule = ["${data.null_data_source.policy.*.outputs}"]
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice(allScopes, true),
+				},
+				FieldPrefix: {
+					Type:     schema.TypeString,
+					Optional: true,
+				},
+				FieldPolicy: {
+					Type: schema.TypeString,
+					// it's required but we have to enforce otherwise due to a bug in terraform
+					// when injecting rules as
+					// rule = ["${data.null_data_source.policy.*.outputs}"]
+					Optional: true,
+				},
+				FieldIntentions: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringInSlice([]string{"read", "write", "deny"}, true),
+				},
+			},
 		},
 	}
 }
 
+// Raw rules are stored in the canonical form and compared semantically,
+// so that formatting, ordering or HCL versus JSON don't show up as diffs
+func rulesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		Computed:         true,
+		ConflictsWith:    []string{FieldRule},
+		ValidateFunc:     validateRules,
+		StateFunc:        normalizeRules,
+		DiffSuppressFunc: suppressEquivalentRules,
+	}
+}
+
+func validateRules(value interface{}, key string) ([]string, []error) {
+	if _, err := rules.Parse(value.(string)); err != nil {
+		return nil, []error{fmt.Errorf("%q cannot be parsed: %s", key, err)}
+	}
+	return nil, nil
+}
+
+// Rules that cannot be parsed are kept as is so that Consul can report what's wrong with them
+func normalizeRules(value interface{}) string {
+	raw := value.(string)
+	parsed, err := rules.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return parsed.HCL()
+}
+
+func suppressEquivalentRules(k, old, new string, d *schema.ResourceData) bool {
+	oldRules, err := rules.Parse(old)
+	if err != nil {
+		return false
+	}
+	newRules, err := rules.Parse(new)
+	if err != nil {
+		return false
+	}
+	return oldRules.Equivalent(newRules)
+}
+
+// Rule blocks take precedence, otherwise raw rules are used
+func getRules(d *schema.ResourceData, singletons []string, prefixForm bool) (string, error) {
+	rawRules := d.Get(FieldRule).(*schema.Set).List()
+	if len(rawRules) == 0 {
+		return d.Get(FieldRules).(string), nil
+	}
+
+	definitions, err := extractRules(rawRules, singletons, prefixForm)
+	if err != nil {
+		return "", err
+	}
+	return definitions.HCL(), nil
+}
+
 func resourceConsulAclTokenCreate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
-	rules, err := extractRules(d.Get(FieldRule).(*schema.Set).List())
+	encoded, err := getRules(d, singletonScopes, false)
 	if err != nil {
 		return err
 	}
@@ -101,12 +188,12 @@ func resourceConsulAclTokenCreate(d *schema.ResourceData, meta interface{}) erro
 		ID:    d.Get(FieldToken).(string),
 		Name:  d.Get(FieldName).(string),
 		Type:  d.Get(FieldType).(string),
-		Rules: encodeRules(rules),
+		Rules: encoded,
 	}
 
 	token, _, err := client.ACL().Create(acl, nil)
 	if err != nil {
-		return err
+		return classifyACLError(client, err)
 	}
 
 	d.SetId(getSHA256(token))
@@ -115,16 +202,19 @@ func resourceConsulAclTokenCreate(d *schema.ResourceData, meta interface{}) erro
 }
 
 func resourceConsulAclTokenRead(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
-	_, err := extractRules(d.Get(FieldRule).(*schema.Set).List())
+	_, err = extractRules(d.Get(FieldRule).(*schema.Set).List(), singletonScopes, false)
 	if err != nil {
 		return err
 	}
 
 	acl, _, err := client.ACL().Info(d.Get(FieldToken).(string), nil)
 	if err != nil {
-		return err
+		return classifyACLError(client, err)
 	}
 
 	if acl == nil {
@@ -135,20 +225,29 @@ func resourceConsulAclTokenRead(d *schema.ResourceData, meta interface{}) error
 	d.Set(FieldName, acl.Name)
 	d.Set(FieldType, acl.Type)
 
-	rules, err := decodeRules(acl.Rules)
-	if err != nil {
-		return err
-	}
+	// Raw rules are only tracked when they are used to define the token, otherwise (as well as upon import) rule blocks are
+	if d.Get(FieldRule).(*schema.Set).Len() == 0 && d.Get(FieldRules).(string) != "" {
+		d.Set(FieldRules, normalizeRules(acl.Rules))
+	} else {
+		definitions, err := decodeRules(acl.Rules)
+		if err != nil {
+			return err
+		}
 
-	d.Set(FieldRule, rules)
+		d.Set(FieldRule, definitions)
+		d.Set(FieldRules, "")
+	}
 
 	return nil
 }
 
 func resourceConsulAclTokenUpdate(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
-	rules, err := extractRules(d.Get(FieldRule).(*schema.Set).List())
+	encoded, err := getRules(d, singletonScopes, false)
 	if err != nil {
 		return err
 	}
@@ -157,19 +256,23 @@ func resourceConsulAclTokenUpdate(d *schema.ResourceData, meta interface{}) erro
 		ID:    d.Get(FieldToken).(string),
 		Name:  d.Get(FieldName).(string),
 		Type:  d.Get(FieldType).(string),
-		Rules: encodeRules(rules),
+		Rules: encoded,
 	}
 
 	_, err = client.ACL().Update(acl, nil)
 	if err != nil {
-		return err
+		return classifyACLError(client, err)
 	}
 
 	return resourceConsulAclTokenRead(d, meta)
 }
 
 func resourceConsulAclTokenDelete(d *schema.ResourceData, meta interface{}) error {
-	acl := meta.(*consul.Client).ACL()
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+	acl := client.ACL()
 	token := d.Get(FieldToken).(string)
 
 	if token == anonymousToken {
@@ -181,19 +284,19 @@ func resourceConsulAclTokenDelete(d *schema.ResourceData, meta interface{}) erro
 		// gets "deleted".
 		aclEntry, _, err := acl.Info(token, nil)
 		if err != nil {
-			return fmt.Errorf("anonymous token not found: %w", err)
+			return fmt.Errorf("anonymous token not found: %w", classifyACLError(client, err))
 		}
 
 		// Reset the rules for token. This gives no permissions on the Consul cluster.
 		aclEntry.Rules = ""
 		_, err = acl.Update(aclEntry, nil)
 		if err != nil {
-			return fmt.Errorf("unable to update anonymous token ACL: %w", err)
+			return fmt.Errorf("unable to update anonymous token ACL: %w", classifyACLError(client, err))
 		}
 	} else {
 		_, err := acl.Destroy(token, nil)
 		if err != nil {
-			return err
+			return classifyACLError(client, err)
 		}
 	}
 
@@ -201,110 +304,78 @@ func resourceConsulAclTokenDelete(d *schema.ResourceData, meta interface{}) erro
 	return nil
 }
 
-// So this one is really ugly. But it's still more convenient that native HCL struct de-serialization
+// Legacy ACLs treat both `key "foo"` and `key_prefix "foo"` as prefix matches so both are decoded the same way
 func decodeRules(raw string) ([]map[string]string, error) {
-	var result []map[string]string
-
-	var policies map[string]interface{}
-
-	err := hcl.Decode(&policies, raw)
+	parsed, err := rules.Parse(raw)
 	if err != nil {
 		return nil, err
 	}
 
-	for scope, scopeDefinitions := range policies {
-		// scopeDefinitions is either of:
-		// {"foo/bar":[{"policy":"write"}]}
-		// "operator":"read"
-
-		defRef := reflect.ValueOf(scopeDefinitions)
-
-		if defRef.Kind() == reflect.String {
-			simplePolicyValue := defRef.String()
-			simplePolicy := map[string]string{FieldScope: scope, FieldPolicy: simplePolicyValue}
-			result = append(result, simplePolicy)
-		} else {
-
-			for i := 0; i < defRef.Len(); i++ {
-				scopePolicyRef := defRef.Index(i)
-
-				prefixRef := scopePolicyRef.MapKeys()[0]
-				// "foo/bar"
-				prefix := prefixRef.String()
-
-				// {"policy":"write"}
-				policyMapRef := scopePolicyRef.MapIndex(prefixRef).Elem().Index(0)
-
-				policyMap := make(map[string]string)
-				for _, k := range policyMapRef.MapKeys() {
-					policyMap[k.String()] = policyMapRef.MapIndex(k).Elem().String()
-				}
-
-				policy, ok := policyMap["policy"]
-				if ok {
-					decodedPolicy := map[string]string{FieldScope: scope, FieldPrefix: prefix, FieldPolicy: policy}
-					result = append(result, decodedPolicy)
-				}
-			}
-		}
-	}
-
-	return result, nil
+	return flattenRules(parsed), nil
 }
 
-// HCL lib does not provide Marshal/Serialize functionality :/
-func encodeRules(rules []map[string]string) string {
-	var result []string
-
-	for _, rule := range rules {
-		policy := strings.ToLower(rule[FieldPolicy])
-		scope := strings.ToLower(rule[FieldScope])
-		prefix, ok := rule[FieldPrefix]
-
-		var ruleStr string
+func flattenRules(parsed rules.Rules) []map[string]string {
+	var result []map[string]string
 
-		if ok {
-			ruleStr = fmt.Sprintf("%s \"%s\" { policy = \"%s\" }", scope, strings.ToLower(prefix), policy)
-		} else {
-			ruleStr = fmt.Sprintf("%s = \"%s\"", scope, policy)
+	for _, rule := range parsed {
+		definition := map[string]string{FieldScope: rule.Resource, FieldPolicy: rule.Policy}
+		if rules.IsPrefixed(rule.Resource) {
+			definition[FieldPrefix] = rule.Segment
 		}
-		result = append(result, ruleStr)
-
+		if rule.Intentions != "" {
+			definition[FieldIntentions] = rule.Intentions
+		}
+		result = append(result, definition)
 	}
-	sort.Strings(result)
-	result = append(result, "")
-	return strings.Join(result, "\n")
+
+	return result
 }
 
-func extractRules(rawRules []interface{}) ([]map[string]string, error) {
+// Converts rule blocks into rules, prefixed scopes are written in the `_prefix` form when prefixForm is set
+func extractRules(rawRules []interface{}, singletons []string, prefixForm bool) (rules.Rules, error) {
 	var allErrors *multierror.Error
 
-	var result []map[string]string
+	var result rules.Rules
 	for _, raw := range rawRules {
 		definition := raw.(map[string]interface{})
 
-		scope := definition[FieldScope].(string)
+		scope := strings.ToLower(definition[FieldScope].(string))
 		if scope == "" {
 			err := fmt.Errorf("the '%s' field is required in: '%v'", FieldScope, definition)
 			allErrors = multierror.Append(allErrors, err)
 		}
 
-		policy := definition[FieldPolicy].(string)
-		if policy == "" {
+		intentions := strings.ToLower(definition[FieldIntentions].(string))
+		policy := strings.ToLower(definition[FieldPolicy].(string))
+		if policy == "" && intentions == "" {
 			err := fmt.Errorf("the '%s' field is required in: '%v'", FieldPolicy, definition)
 			allErrors = multierror.Append(allErrors, err)
 		}
 
 		prefix := definition[FieldPrefix].(string)
-		rule := map[string]string{FieldScope: scope, FieldPolicy: policy}
+		rule := rules.Rule{Resource: scope, Policy: policy, Intentions: intentions}
 
 		if stringInSlice(scope, prefixedScopes) {
-			rule[FieldPrefix] = strings.ToLower(prefix)
+			rule.Segment = prefix
+			rule.Prefix = prefixForm
+		} else if !stringInSlice(scope, singletons) {
+			if scope != "" {
+				err := fmt.Errorf("the scope %q is not supported, expected one of %s, %s: %v", scope,
+					strings.Join(prefixedScopes, ", "), strings.Join(singletons, ", "), definition)
+				allErrors = multierror.Append(allErrors, err)
+			}
+			continue
 		} else if prefix != "" {
-			err := fmt.Errorf("the 'prefix' field is not allowed on scopes %s: %v", strings.Join(singletonScopes, ", "), definition)
+			err := fmt.Errorf("the 'prefix' field is not allowed on scopes %s: %v", strings.Join(singletons, ", "), definition)
 			allErrors = multierror.Append(allErrors, err)
 		}
 
+		if scope != "" && (policy != "" || intentions != "") {
+			if err := rule.Validate(); err != nil {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("%s: '%v'", err, definition))
+			}
+		}
+
 		result = append(result, rule)
 	}
 
@@ -324,7 +395,7 @@ func stringInSlice(str string, list []string) bool {
 func diffResource(d *schema.ResourceDiff, m interface{}) error {
 	_, newRules := d.GetChange(FieldRule)
 
-	_, err := extractRules(newRules.(*schema.Set).List())
+	_, err := extractRules(newRules.(*schema.Set).List(), singletonScopes, false)
 	if err != nil {
 		return err
 	}
@@ -2,8 +2,11 @@ package consulacl
 
 import (
 	"fmt"
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
 	consul "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/terraform/helper/schema"
+	"log"
+	"time"
 )
 
 func resourceConsulAclToken14() *schema.Resource {
@@ -13,9 +16,11 @@ func resourceConsulAclToken14() *schema.Resource {
 		Update: resourceConsulAclToken14Update,
 		Delete: resourceConsulAclToken14Delete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceConsulAclToken14Import,
 		},
 
+		CustomizeDiff: diffToken14Resource,
+
 		Schema: map[string]*schema.Schema{
 			FieldAccessor: {
 				Type:     schema.TypeString,
@@ -41,24 +46,82 @@ func resourceConsulAclToken14() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			FieldPoliciesAuthoritative: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether policies not declared in the configuration should be removed from the token",
+			},
+			FieldRoles: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Role names or IDs",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			FieldRolesAuthoritative: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether roles not declared in the configuration should be removed from the token",
+			},
+			FieldServiceIdentity: serviceIdentitySchema(),
 			FieldLocal: {
 				Type:     schema.TypeBool,
 				ForceNew: true,
 				Optional: true,
 				Default:  false,
 			},
+			FieldExpirationTTL: {
+				Type:         schema.TypeString,
+				ForceNew:     true,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Duration after which the token expires, e.g. '24h'",
+			},
+			FieldRenewBefore: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "Window before the expiration time during which the token is planned for replacement",
+			},
+			FieldExpirationTime: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldCreateTime: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldEffectiveRules: {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			FieldWaitForReplication: waitForReplicationSchema("Datacenters to wait for the token to be replicated to, overrides the provider's default"),
+			FieldDatacenter:         datacenterSchema(),
 		},
 	}
 }
 
 func resourceConsulACLToken14Create(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	aclToken := consul.ACLToken{
-		AccessorID:  d.Get(FieldAccessor).(string),
-		SecretID:    d.Get(FieldSecret).(string),
-		Description: d.Get(FieldDescription).(string),
-		Local:       d.Get(FieldLocal).(bool),
+		AccessorID:        d.Get(FieldAccessor).(string),
+		SecretID:          d.Get(FieldSecret).(string),
+		Description:       d.Get(FieldDescription).(string),
+		Local:             d.Get(FieldLocal).(bool),
+		Roles:             expandTokenRoleLinks(d.Get(FieldRoles).(*schema.Set).List()),
+		ServiceIdentities: expandServiceIdentities(d.Get(FieldServiceIdentity).(*schema.Set).List()),
+	}
+
+	if ttl := d.Get(FieldExpirationTTL).(string); ttl != "" {
+		// already validated by the schema
+		aclToken.ExpirationTTL, _ = time.ParseDuration(ttl)
 	}
 
 	iPolicies := d.Get(FieldPolicies).(*schema.Set).List()
@@ -75,21 +138,35 @@ func resourceConsulACLToken14Create(d *schema.ResourceData, meta interface{}) er
 
 	token, _, err := client.ACL().TokenCreate(&aclToken, nil)
 	if err != nil {
-		return fmt.Errorf("error creating ACL token: %s", err)
+		return fmt.Errorf("error creating ACL token: %s", classifyACLError(client, err))
 	}
 
 	d.SetId(token.AccessorID)
 
+	if err = waitForToken14Replication(d, meta); err != nil {
+		return err
+	}
+
 	return resourceConsulAclToken14Read(d, meta)
 }
 
 func resourceConsulAclToken14Read(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	id := d.Id()
 
 	aclToken, _, err := client.ACL().TokenRead(id, nil)
 	if err != nil {
+		err = classifyACLError(client, err)
+		if !isNotFoundError(err) {
+			return fmt.Errorf("error reading ACL token %q: %s", id, err)
+		}
+		if expired, _ := isTokenExpired(d.Get(FieldExpirationTime).(string), 0); expired {
+			log.Printf("[WARN] ACL token %q has expired and will be re-created", id)
+		}
 		d.SetId("")
 		return nil
 	}
@@ -106,61 +183,327 @@ func resourceConsulAclToken14Read(d *schema.ResourceData, meta interface{}) erro
 		return fmt.Errorf("error while setting %q: %s", FieldDescription, err)
 	}
 
+	authoritative := d.Get(FieldPoliciesAuthoritative).(bool)
+	declaredPolicies := d.Get(FieldPolicies).(*schema.Set)
 	policies := make([]string, 0, len(aclToken.Policies))
 	for _, policyLink := range aclToken.Policies {
-		policies = append(policies, policyLink.Name)
+		// policies bound by other resources are ignored unless the token owns the complete set
+		if authoritative || declaredPolicies.Contains(policyLink.Name) {
+			policies = append(policies, policyLink.Name)
+		}
 	}
 
 	if err = d.Set(FieldPolicies, policies); err != nil {
 		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
 	}
+
+	authoritativeRoles := d.Get(FieldRolesAuthoritative).(bool)
+	configuredRoles := d.Get(FieldRoles).(*schema.Set)
+	roles := make([]string, 0, len(aclToken.Roles))
+	for _, roleLink := range aclToken.Roles {
+		// same as policies, roles bound by other resources are only tracked by tokens owning the complete set
+		if authoritativeRoles || isLinkDeclared(configuredRoles, roleLink.ID, roleLink.Name) {
+			roles = append(roles, linkReference(configuredRoles, roleLink.ID, roleLink.Name))
+		}
+	}
+
+	if err = d.Set(FieldRoles, roles); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldRoles, err)
+	}
+
+	if err = d.Set(FieldServiceIdentity, flattenServiceIdentities(aclToken.ServiceIdentities)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldServiceIdentity, err)
+	}
+
 	if err = d.Set(FieldLocal, aclToken.Local); err != nil {
 		return fmt.Errorf("error while setting %q: %s", FieldLocal, err)
 	}
 
+	expirationTime := ""
+	if aclToken.ExpirationTime != nil {
+		expirationTime = aclToken.ExpirationTime.Format(time.RFC3339)
+	}
+	if err = d.Set(FieldExpirationTime, expirationTime); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldExpirationTime, err)
+	}
+
+	if err = d.Set(FieldCreateTime, aclToken.CreateTime.Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldCreateTime, err)
+	}
+
+	effectiveRules, err := effectiveTokenRules(client, d.Get(FieldDatacenter).(string), aclToken)
+	if err != nil {
+		return err
+	}
+
+	if err = d.Set(FieldEffectiveRules, effectiveRules); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldEffectiveRules, err)
+	}
+
 	return nil
 }
 
 func resourceConsulAclToken14Update(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	id := d.Id()
 
-	aclToken := consul.ACLToken{
-		AccessorID:  id,
-		SecretID:    d.Get(FieldSecret).(string),
-		Description: d.Get(FieldDescription).(string),
-		Local:       d.Get(FieldLocal).(bool),
-	}
-
-	if v, ok := d.GetOk(FieldPolicies); ok {
-		vs := v.(*schema.Set).List()
-		s := make([]*consul.ACLTokenPolicyLink, len(vs))
-		for i, raw := range vs {
-			s[i] = &consul.ACLTokenPolicyLink{
-				Name: raw.(string),
-			}
+	// Policies and roles might be bound to the token by other resources so the update has to be serialized with them
+	err = mutateToken(client, id, func(aclToken *consul.ACLToken) bool {
+		secret := d.Get(FieldSecret).(string)
+		description := d.Get(FieldDescription).(string)
+		local := d.Get(FieldLocal).(bool)
+		policies := updateToken14PolicyLinks(d, aclToken.Policies)
+		roles := updateToken14RoleLinks(d, aclToken.Roles)
+		identities := expandServiceIdentities(d.Get(FieldServiceIdentity).(*schema.Set).List())
+
+		if aclToken.SecretID == secret && aclToken.Description == description && aclToken.Local == local &&
+			sameLinks(tokenPolicyObjectLinks(aclToken.Policies), tokenPolicyObjectLinks(policies)) &&
+			sameLinks(tokenRoleObjectLinks(aclToken.Roles), tokenRoleObjectLinks(roles)) &&
+			sameServiceIdentities(aclToken.ServiceIdentities, identities) {
+			return false
 		}
-		aclToken.Policies = s
-	}
 
-	_, _, err := client.ACL().TokenUpdate(&aclToken, nil)
+		aclToken.SecretID = secret
+		aclToken.Description = description
+		aclToken.Local = local
+		aclToken.Policies = policies
+		aclToken.Roles = roles
+		aclToken.ServiceIdentities = identities
+		// expiration cannot be changed once the token is created
+		aclToken.ExpirationTTL = 0
+		return true
+	})
 	if err != nil {
 		return fmt.Errorf("error updating ACL token %q: %s", id, err)
 	}
 
+	if err = waitForToken14Replication(d, meta); err != nil {
+		return err
+	}
+
 	return resourceConsulAclToken14Read(d, meta)
 }
 
+func waitForToken14Replication(d *schema.ResourceData, meta interface{}) error {
+	// local tokens are never replicated
+	if d.Get(FieldLocal).(bool) {
+		return nil
+	}
+
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	wait, err := getReplicationWait(d, meta.(*Meta))
+	if err != nil {
+		return err
+	}
+
+	return waitForReplication(client, wait, "ACL token", true, func(q *consul.QueryOptions) ([]byte, error) {
+		aclToken, _, err := client.ACL().TokenRead(d.Id(), q)
+		if err != nil {
+			err = classifyACLError(client, err)
+			if isNotFoundError(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return aclToken.Hash, nil
+	})
+}
+
 func resourceConsulAclToken14Delete(d *schema.ResourceData, meta interface{}) error {
-	client := meta.(*consul.Client)
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
 
 	id := d.Id()
 
-	_, err := client.ACL().TokenDelete(id, nil)
+	_, err = client.ACL().TokenDelete(id, nil)
 	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			return nil
+		}
 		return fmt.Errorf("error deleting ACL token %q: %s", id, err)
 	}
 
 	return nil
 }
+
+// Defaults are not applied on import so an imported token would otherwise be non-authoritative until the next apply
+func resourceConsulAclToken14Import(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	for _, field := range []string{FieldPoliciesAuthoritative, FieldRolesAuthoritative} {
+		if err := d.Set(field, true); err != nil {
+			return nil, fmt.Errorf("error while setting %q: %s", field, err)
+		}
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandTokenRoleLinks(rawRoles []interface{}) []*consul.ACLTokenRoleLink {
+	var result []*consul.ACLTokenRoleLink
+	for _, raw := range rawRoles {
+		id, name := parseLinkReference(raw.(string))
+		result = append(result, &consul.ACLTokenRoleLink{ID: id, Name: name})
+	}
+	return result
+}
+
+// Plans replacement of tokens that are about to expire within the renewal window
+func diffToken14Resource(d *schema.ResourceDiff, meta interface{}) error {
+	if err := diffToken14EffectiveRules(d, meta); err != nil {
+		return err
+	}
+	return diffToken14Expiration(d)
+}
+
+// Shows the permissions the token is going to get once its links change
+func diffToken14EffectiveRules(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.HasChange(FieldPolicies) && !d.HasChange(FieldRoles) && !d.HasChange(FieldServiceIdentity) {
+		return nil
+	}
+
+	// Links of tokens that don't own the complete set of policies or roles cannot be known upfront, neither can
+	// unknown values
+	if !d.Get(FieldPoliciesAuthoritative).(bool) || !d.Get(FieldRolesAuthoritative).(bool) ||
+		!d.NewValueKnown(FieldPolicies) || !d.NewValueKnown(FieldRoles) || !d.NewValueKnown(FieldServiceIdentity) {
+		return d.SetNewComputed(FieldEffectiveRules)
+	}
+
+	// unknown until the token is created if not set, the provider's default is going to be used then
+	dc := d.Get(FieldDatacenter).(string)
+	if dc == "" {
+		var err error
+		if dc, err = meta.(*Meta).defaultDatacenter(); err != nil {
+			return err
+		}
+	}
+
+	client, err := meta.(*Meta).datacenterClient(dc)
+	if err != nil {
+		return err
+	}
+
+	collected, err := collectRules(
+		client,
+		dc,
+		interfacesToStrings(d.Get(FieldPolicies).(*schema.Set).List()),
+		interfacesToStrings(d.Get(FieldRoles).(*schema.Set).List()),
+		expandServiceIdentities(d.Get(FieldServiceIdentity).(*schema.Set).List()),
+	)
+	if err != nil {
+		// reads leave rules that cannot be computed for other reasons empty
+		if d.Id() != "" && !isNotFoundError(err) && !isACLErrorKind(err, aclErrorUnavailable) {
+			log.Printf("[DEBUG] Effective rules of ACL token %q cannot be computed: %s", d.Id(), err)
+			return d.SetNew(FieldEffectiveRules, "")
+		}
+		// e.g. policies or roles that are going to be created by the same run
+		log.Printf("[DEBUG] Effective rules of ACL token %q will be known after apply: %s", d.Id(), err)
+		return d.SetNewComputed(FieldEffectiveRules)
+	}
+
+	return d.SetNew(FieldEffectiveRules, rules.Merge(collected...).HCL())
+}
+
+func diffToken14Expiration(d *schema.ResourceDiff) error {
+	if d.Id() == "" {
+		return nil
+	}
+
+	renewBefore := d.Get(FieldRenewBefore).(string)
+	if renewBefore == "" {
+		return nil
+	}
+
+	window, err := time.ParseDuration(renewBefore)
+	if err != nil {
+		return err
+	}
+
+	expired, err := isTokenExpired(d.Get(FieldExpirationTime).(string), window)
+	if err != nil {
+		return err
+	}
+
+	if !expired {
+		return nil
+	}
+
+	if err = d.SetNewComputed(FieldExpirationTime); err != nil {
+		return err
+	}
+	return d.ForceNew(FieldExpirationTime)
+}
+
+// Tells whether a token with the given expiration time (if any) expires within the window from now
+func isTokenExpired(expirationTime string, window time.Duration) (bool, error) {
+	if expirationTime == "" {
+		return false, nil
+	}
+
+	expiration, err := time.Parse(time.RFC3339, expirationTime)
+	if err != nil {
+		return false, fmt.Errorf("cannot parse token expiration time %q: %s", expirationTime, err)
+	}
+
+	return !time.Now().Add(window).Before(expiration), nil
+}
+
+func validateDuration(v interface{}, k string) (ws []string, errors []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a valid duration: %s", k, err))
+	}
+	return
+}
+
+// In authoritative mode declared policies replace all the token's policies, otherwise only policies
+// that were added to or removed from the configuration are changed and the rest is left intact
+func updateToken14PolicyLinks(d *schema.ResourceData, current []*consul.ACLTokenPolicyLink) []*consul.ACLTokenPolicyLink {
+	oldPolicies, newPolicies := d.GetChange(FieldPolicies)
+	previous := oldPolicies.(*schema.Set)
+	declared := newPolicies.(*schema.Set)
+
+	var result []*consul.ACLTokenPolicyLink
+
+	if !d.Get(FieldPoliciesAuthoritative).(bool) {
+		for _, policyLink := range current {
+			if !previous.Contains(policyLink.Name) && !declared.Contains(policyLink.Name) {
+				result = append(result, policyLink)
+			}
+		}
+	}
+
+	for _, raw := range declared.List() {
+		result = append(result, &consul.ACLTokenPolicyLink{
+			Name: raw.(string),
+		})
+	}
+
+	return result
+}
+
+// Same as updateToken14PolicyLinks but for roles that can be referenced either by names or by IDs
+func updateToken14RoleLinks(d *schema.ResourceData, current []*consul.ACLTokenRoleLink) []*consul.ACLTokenRoleLink {
+	oldRoles, newRoles := d.GetChange(FieldRoles)
+	previous := oldRoles.(*schema.Set)
+	declared := newRoles.(*schema.Set)
+
+	var result []*consul.ACLTokenRoleLink
+
+	if !d.Get(FieldRolesAuthoritative).(bool) {
+		for _, roleLink := range current {
+			if !isLinkDeclared(previous, roleLink.ID, roleLink.Name) && !isLinkDeclared(declared, roleLink.ID, roleLink.Name) {
+				result = append(result, roleLink)
+			}
+		}
+	}
+
+	return append(result, expandTokenRoleLinks(declared.List())...)
+}
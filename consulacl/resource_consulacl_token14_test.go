@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/ashald/terraform-provider-consulacl/consulacl"
 	"github.com/hashicorp/terraform/terraform"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/resource"
@@ -28,9 +29,33 @@ resource "consulacl_token14" "test" {
 }
 `
 
+const resourceAclToken14ConfigIdentities = `
+resource "consulacl_role" "test" {
+	name = "test-token14-role"
+}
+
+resource "consulacl_token14" "test" {
+	accessor = "86bfeed0-bc8f-4bd6-8dcb-e4f404409a4d"
+	secret = "fadfa76c-e894-4bea-8ac7-5249356e148b"
+	description = "Test Token Identities"
+	roles = ["${consulacl_role.test.name}"]
+
+	service_identity {
+		service_name = "web"
+		datacenters = ["dc1"]
+	}
+}
+`
+
+const resourceAclToken14EffectiveRulesIdentities = `node_prefix "" { policy = "read" }
+service "web" { policy = "write" }
+service "web-sidecar-proxy" { policy = "write" }
+service_prefix "" { policy = "read" }
+`
+
 func TestIntegrationResourceToken14(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		IsUnitTest: false,
+		IsUnitTest: testAgainstFake,
 		Providers:  testProviders,
 		PreCheck:   func() { testResourcePreConfig(t) },
 		CheckDestroy: testResourceToken14Absent(resourceAclToken14Accessor),
@@ -42,6 +67,7 @@ func TestIntegrationResourceToken14(t *testing.T) {
 					resource.TestCheckResourceAttr("consulacl_token14.test", consulacl.FieldSecret, resourceAclToken14Secret),
 					resource.TestCheckResourceAttr("consulacl_token14.test", consulacl.FieldDescription, "Test Token Initial"),
 					resource.TestCheckResourceAttr("consulacl_token14.test", "policies.#", "0"),
+					resource.TestCheckResourceAttr("consulacl_token14.test", consulacl.FieldEffectiveRules, ""),
 				),
 			},
 			{
@@ -53,8 +79,24 @@ func TestIntegrationResourceToken14(t *testing.T) {
 					resource.TestCheckResourceAttr("consulacl_token14.test", "policies.#", "1"),
 					// 'policies' is a set and '938696404' appears to be a hash-value of the string 'global-management' ¯\_(ツ)_/¯
 					resource.TestCheckResourceAttr("consulacl_token14.test", "policies.938696404", "global-management"),
+					resource.TestMatchResourceAttr("consulacl_token14.test", consulacl.FieldEffectiveRules, regexp.MustCompile(`(?m)^acl = "write"$`)),
 				),
 			},
+			{
+				Config: resourceAclToken14ConfigIdentities,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.test", consulacl.FieldDescription, "Test Token Identities"),
+					resource.TestCheckResourceAttr("consulacl_token14.test", "policies.#", "0"),
+					resource.TestCheckResourceAttr("consulacl_token14.test", "roles.#", "1"),
+					resource.TestCheckResourceAttr("consulacl_token14.test", "service_identity.#", "1"),
+					resource.TestCheckResourceAttr("consulacl_token14.test", consulacl.FieldEffectiveRules, resourceAclToken14EffectiveRulesIdentities),
+				),
+			},
+			{
+				ResourceName:      "consulacl_token14.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
 		},
 	})
 }
@@ -73,3 +115,186 @@ func testResourceToken14Absent(accessor string) resource.TestCheckFunc {
 		return nil
 	}
 }
+
+const resourceAclToken14ExpiringAccessor = "b4e7a4f6-4bc1-4a43-9e1f-3a4a3d5c0e0b"
+const resourceAclToken14ConfigExpiring = `
+resource "consulacl_token14" "expiring" {
+	accessor = "b4e7a4f6-4bc1-4a43-9e1f-3a4a3d5c0e0b"
+	description = "Test Expiring Token"
+	expiration_ttl = "1h"
+}
+`
+
+const resourceAclToken14ConfigRenewing = `
+resource "consulacl_token14" "expiring" {
+	accessor = "b4e7a4f6-4bc1-4a43-9e1f-3a4a3d5c0e0b"
+	description = "Test Expiring Token"
+	expiration_ttl = "1h"
+	renew_before = "2h"
+}
+`
+
+func TestIntegrationResourceToken14Expiration(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceToken14Absent(resourceAclToken14ExpiringAccessor),
+		Steps: []resource.TestStep{
+			{
+				Config: resourceAclToken14ConfigExpiring,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.expiring", consulacl.FieldExpirationTTL, "1h"),
+					resource.TestCheckResourceAttrSet("consulacl_token14.expiring", consulacl.FieldExpirationTime),
+					resource.TestCheckResourceAttrSet("consulacl_token14.expiring", consulacl.FieldCreateTime),
+				),
+			},
+			{
+				// the token expires within the renewal window so it has to be replaced
+				Config:             resourceAclToken14ConfigRenewing,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+const resourceAclToken14NonAuthoritativeAccessor = "c3a1e5d7-2b4f-4c6e-8a0b-1d3f5e7a9c2b"
+const resourceAclToken14ConfigNonAuthoritative = `
+resource "consulacl_policy" "bound" {
+	name = "test-token14-bound"
+	rules = "node_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulacl_token14" "shared" {
+	accessor = "c3a1e5d7-2b4f-4c6e-8a0b-1d3f5e7a9c2b"
+	description = "Test Shared Token"
+	policies = ["global-management"]
+	policies_authoritative = false
+}
+
+resource "consulacl_policy_binding" "bound" {
+	accessor = "${consulacl_token14.shared.accessor}"
+	policy = "${consulacl_policy.bound.name}"
+}
+`
+
+const resourceAclToken14ConfigNonAuthoritativeEmpty = `
+resource "consulacl_policy" "bound" {
+	name = "test-token14-bound"
+	rules = "node_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulacl_token14" "shared" {
+	accessor = "c3a1e5d7-2b4f-4c6e-8a0b-1d3f5e7a9c2b"
+	description = "Test Shared Token"
+	policies_authoritative = false
+}
+
+resource "consulacl_policy_binding" "bound" {
+	accessor = "${consulacl_token14.shared.accessor}"
+	policy = "${consulacl_policy.bound.name}"
+}
+`
+
+func TestIntegrationResourceToken14NonAuthoritative(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceToken14Absent(resourceAclToken14NonAuthoritativeAccessor),
+		Steps: []resource.TestStep{
+			{
+				// the policy bound by another resource must not cause a perpetual diff
+				Config: resourceAclToken14ConfigNonAuthoritative,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.shared", "policies.#", "1"),
+					testResourcePolicyBinding(resourceAclToken14NonAuthoritativeAccessor, "global-management", true),
+					testResourcePolicyBinding(resourceAclToken14NonAuthoritativeAccessor, "test-token14-bound", true),
+				),
+			},
+			{
+				// removing all declared policies must really remove them but keep the bound one
+				Config: resourceAclToken14ConfigNonAuthoritativeEmpty,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.shared", "policies.#", "0"),
+					testResourcePolicyBinding(resourceAclToken14NonAuthoritativeAccessor, "global-management", false),
+					testResourcePolicyBinding(resourceAclToken14NonAuthoritativeAccessor, "test-token14-bound", true),
+				),
+			},
+		},
+	})
+}
+
+const resourceAclToken14RolesNonAuthoritativeAccessor = "5e9b2d4f-7a1c-4f3e-9d6b-2c8a0e4f6b1d"
+const resourceAclToken14ConfigRolesNonAuthoritative = `
+resource "consulacl_role" "declared" {
+	name = "test-token14-declared"
+}
+
+resource "consulacl_role" "bound" {
+	name = "test-token14-role-bound"
+}
+
+resource "consulacl_token14" "shared" {
+	accessor = "5e9b2d4f-7a1c-4f3e-9d6b-2c8a0e4f6b1d"
+	description = "Test Shared Token"
+	roles = ["${consulacl_role.declared.name}"]
+	roles_authoritative = false
+}
+
+resource "consulacl_role_binding" "bound" {
+	accessor = "${consulacl_token14.shared.accessor}"
+	role = "${consulacl_role.bound.name}"
+}
+`
+
+const resourceAclToken14ConfigRolesNonAuthoritativeEmpty = `
+resource "consulacl_role" "declared" {
+	name = "test-token14-declared"
+}
+
+resource "consulacl_role" "bound" {
+	name = "test-token14-role-bound"
+}
+
+resource "consulacl_token14" "shared" {
+	accessor = "5e9b2d4f-7a1c-4f3e-9d6b-2c8a0e4f6b1d"
+	description = "Test Shared Token"
+	roles_authoritative = false
+}
+
+resource "consulacl_role_binding" "bound" {
+	accessor = "${consulacl_token14.shared.accessor}"
+	role = "${consulacl_role.bound.name}"
+}
+`
+
+func TestIntegrationResourceToken14RolesNonAuthoritative(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:   testAgainstFake,
+		Providers:    testProviders,
+		PreCheck:     func() { testResourcePreConfig(t) },
+		CheckDestroy: testResourceToken14Absent(resourceAclToken14RolesNonAuthoritativeAccessor),
+		Steps: []resource.TestStep{
+			{
+				// the role bound by another resource must not cause a perpetual diff
+				Config: resourceAclToken14ConfigRolesNonAuthoritative,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.shared", "roles.#", "1"),
+					testResourceRoleBinding(resourceAclToken14RolesNonAuthoritativeAccessor, "test-token14-declared", true),
+					testResourceRoleBinding(resourceAclToken14RolesNonAuthoritativeAccessor, "test-token14-role-bound", true),
+				),
+			},
+			{
+				// removing all declared roles must really remove them but keep the bound one
+				Config: resourceAclToken14ConfigRolesNonAuthoritativeEmpty,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token14.shared", "roles.#", "0"),
+					testResourceRoleBinding(resourceAclToken14RolesNonAuthoritativeAccessor, "test-token14-declared", false),
+					testResourceRoleBinding(resourceAclToken14RolesNonAuthoritativeAccessor, "test-token14-role-bound", true),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,167 @@
+package consulacl
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func resourceConsulAclTokenPolicies() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceConsulAclTokenPoliciesCreate,
+		Read:   resourceConsulAclTokenPoliciesRead,
+		Update: resourceConsulAclTokenPoliciesUpdate,
+		Delete: resourceConsulAclTokenPoliciesDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Schema: map[string]*schema.Schema{
+			FieldAccessor: {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Token's accessor ID",
+			},
+			FieldPolicies: {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The complete set of policy names or IDs the token is linked to",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			FieldDatacenter: datacenterSchema(),
+		},
+	}
+}
+
+func resourceConsulAclTokenPoliciesCreate(d *schema.ResourceData, meta interface{}) error {
+	accessor := d.Get(FieldAccessor).(string)
+
+	if err := setTokenPolicies(d, meta, accessor); err != nil {
+		return err
+	}
+
+	d.SetId(accessor)
+
+	return resourceConsulAclTokenPoliciesRead(d, meta)
+}
+
+func resourceConsulAclTokenPoliciesRead(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	aclToken, _, err := client.ACL().TokenRead(id, nil)
+	if err != nil {
+		err = classifyACLError(client, err)
+		if isNotFoundError(err) {
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("error reading ACL token %q: %s", id, err)
+	}
+
+	if err = d.Set(FieldAccessor, aclToken.AccessorID); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldAccessor, err)
+	}
+
+	configured := d.Get(FieldPolicies).(*schema.Set)
+	policies := make([]string, 0, len(aclToken.Policies))
+	for _, policyLink := range aclToken.Policies {
+		policies = append(policies, linkReference(configured, policyLink.ID, policyLink.Name))
+	}
+
+	if err = d.Set(FieldPolicies, policies); err != nil {
+		return fmt.Errorf("error while setting %q: %s", FieldPolicies, err)
+	}
+
+	return nil
+}
+
+func resourceConsulAclTokenPoliciesUpdate(d *schema.ResourceData, meta interface{}) error {
+	if err := setTokenPolicies(d, meta, d.Id()); err != nil {
+		return err
+	}
+
+	return resourceConsulAclTokenPoliciesRead(d, meta)
+}
+
+func resourceConsulAclTokenPoliciesDelete(d *schema.ResourceData, meta interface{}) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+
+	err = mutateToken(client, id, func(aclToken *consul.ACLToken) bool {
+		if len(aclToken.Policies) == 0 {
+			return false
+		}
+
+		aclToken.Policies = nil
+		return true
+	})
+	if isNotFoundError(err) {
+		return nil // token not found but it also means there are no policies
+	}
+	if err != nil {
+		return fmt.Errorf("error removing policies from ACL token %q: %s", id, err)
+	}
+
+	return nil
+}
+
+// Replaces all policy links of the token with the ones from the configuration
+func setTokenPolicies(d *schema.ResourceData, meta interface{}, accessor string) error {
+	client, err := getClient(d, meta)
+	if err != nil {
+		return err
+	}
+
+	configured := d.Get(FieldPolicies).(*schema.Set)
+
+	err = mutateToken(client, accessor, func(aclToken *consul.ACLToken) bool {
+		current := make([]string, 0, len(aclToken.Policies))
+		for _, policyLink := range aclToken.Policies {
+			current = append(current, linkReference(configured, policyLink.ID, policyLink.Name))
+		}
+
+		if configured.Equal(schema.NewSet(configured.F, stringsToInterfaces(current))) {
+			return false
+		}
+
+		aclToken.Policies = nil
+		for _, raw := range configured.List() {
+			id, name := parseLinkReference(raw.(string))
+			aclToken.Policies = append(aclToken.Policies, &consul.ACLTokenPolicyLink{ID: id, Name: name})
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("error setting policies of ACL token %q: %s", accessor, err)
+	}
+
+	return nil
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	result := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
+
+func interfacesToStrings(values []interface{}) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.(string))
+	}
+	return result
+}
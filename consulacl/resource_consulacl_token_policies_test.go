@@ -0,0 +1,123 @@
+package consulacl_test
+
+import (
+	"fmt"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/hashicorp/terraform/terraform"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const resourceTokenPoliciesAccessor = "9f4e2a7c-1d3b-4e5f-a6b7-c8d9e0f1a2b3"
+
+const resourceTokenPolicies = `
+resource "consulacl_policy" "test" {
+  name = "test-token-policies"
+  rules = "node_prefix \"\" { policy = \"read\" }"
+}
+
+resource "consulacl_token_policies" "test" {
+  accessor = "9f4e2a7c-1d3b-4e5f-a6b7-c8d9e0f1a2b3"
+  policies = ["${consulacl_policy.test.name}"]
+}
+`
+
+func TestIntegrationTokenPolicies(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: testAgainstFake,
+		Providers:  testProviders,
+		PreCheck:   func() { testResourceTokenPoliciesPreConfig(t) },
+		CheckDestroy: func(s *terraform.State) error {
+			_, err := testClient.ACL().TokenDelete(resourceTokenPoliciesAccessor, nil)
+			return err
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: resourceTokenPolicies,
+				Check: resource.ComposeTestCheckFunc(
+					// the policy linked by hand has to be removed
+					testResourceTokenPolicies(resourceTokenPoliciesAccessor, "test-token-policies"),
+				),
+			},
+			{
+				ResourceName:      "consulacl_token_policies.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateId:     resourceTokenPoliciesAccessor,
+			},
+			{
+				Config:             resourceTokenPolicies,
+				ExpectNonEmptyPlan: true,
+				Check: resource.ComposeTestCheckFunc(
+					// Change token in Consul bypassing Terraform
+					testResourceTokenPoliciesLink(resourceTokenPoliciesAccessor, "global-management"),
+				),
+			},
+			{
+				Config: resourceTokenPolicies,
+				Check: resource.ComposeTestCheckFunc(
+					testResourceTokenPolicies(resourceTokenPoliciesAccessor, "test-token-policies"),
+				),
+			},
+			{
+				Config: "locals {}",
+				Check: resource.ComposeTestCheckFunc(
+					testResourceTokenPolicies(resourceTokenPoliciesAccessor),
+				),
+			},
+		},
+	})
+}
+
+func testResourceTokenPoliciesPreConfig(t *testing.T) {
+	testResourcePreConfig(t)
+
+	token := &consul.ACLToken{
+		AccessorID:  resourceTokenPoliciesAccessor,
+		Description: "Test Token Policies",
+		Policies:    []*consul.ACLTokenPolicyLink{{Name: "global-management"}},
+	}
+
+	_, _, err := testClient.ACL().TokenCreate(token, nil)
+	if err != nil {
+		t.Fatal("Cannot provision a test token for consulacl_token_policies resource test", err)
+	}
+}
+
+func testResourceTokenPoliciesLink(accessor, policy string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		aclToken, _, err := testClient.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return err
+		}
+
+		aclToken.Policies = append(aclToken.Policies, &consul.ACLTokenPolicyLink{Name: policy})
+		_, _, err = testClient.ACL().TokenUpdate(aclToken, nil)
+		return err
+	}
+}
+
+func testResourceTokenPolicies(accessor string, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		aclToken, _, err := testClient.ACL().TokenRead(accessor, nil)
+		if err != nil {
+			return err
+		}
+
+		var actual []string
+		for _, policyLink := range aclToken.Policies {
+			actual = append(actual, policyLink.Name)
+		}
+
+		sort.Strings(actual)
+		sort.Strings(expected)
+
+		if strings.Join(actual, ",") != strings.Join(expected, ",") {
+			return fmt.Errorf("ACL token %q was expected to have policies %v but has %v", accessor, expected, actual)
+		}
+
+		return nil
+	}
+}
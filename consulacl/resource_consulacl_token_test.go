@@ -54,6 +54,22 @@ resource "consulacl_token" "token" {
 }
 `
 
+const aclTokenConfigRawRules = `
+resource "consulacl_token" "token" {
+  name  = "Updated token"
+  token = "my-custom-token"
+  type  = "management"
+
+  rules = <<EOF
+{
+  "service": {"some/path": {"policy": "read"}},
+  "key": {"": {"policy": "write"}},
+  "keyring": "write"
+}
+EOF
+}
+`
+
 const rulesOriginal = `key "foo/bar/baz" { policy = "write" }
 operator = "read"
 service "" { policy = "read" }
@@ -65,8 +81,9 @@ service "some/path" { policy = "read" }
 
 func TestIntegrationToken(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testResourcePreConfig(t) },
-		Providers: testProviders,
+		IsUnitTest: testAgainstFake,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Providers:  testProviders,
 		CheckDestroy: resource.ComposeTestCheckFunc(
 			testConsulAclTokenAbsent("my-custom-token"),
 		),
@@ -119,6 +136,14 @@ func TestIntegrationToken(t *testing.T) {
 					checkTokenConfig("my-custom-token", "rules", rulesUpdated),
 				),
 			},
+			{
+				// Consul keeps raw rules as JSON while the state has them in the canonical form
+				Config: aclTokenConfigRawRules,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("consulacl_token.token", "rules", rulesUpdated),
+					resource.TestCheckResourceAttr("consulacl_token.token", "rule.#", "0"),
+				),
+			},
 		},
 	})
 }
@@ -138,8 +163,9 @@ resource "consulacl_token" "imported" {
 
 func TestIntegrationTokenImport(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testResourcePreConfig(t) },
-		Providers: testProviders,
+		IsUnitTest: testAgainstFake,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Providers:  testProviders,
 		CheckDestroy: resource.ComposeTestCheckFunc(
 			testConsulAclTokenAbsent("my-imported-token"),
 		),
@@ -173,8 +199,9 @@ resource "consulacl_token" "anonymous" {
 
 func TestIntegrationTokenAnonymous(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:  func() { testResourcePreConfig(t) },
-		Providers: testProviders,
+		IsUnitTest: testAgainstFake,
+		PreCheck:   func() { testResourcePreConfig(t) },
+		Providers:  testProviders,
 		CheckDestroy: resource.ComposeTestCheckFunc(
 			testConsulAnonymousEmpty,
 		),
@@ -0,0 +1,147 @@
+package consulacl
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Initial wait between attempts that doubles with every retry until it reaches the configured maximum
+const retryBaseWait = 250 * time.Millisecond
+
+// Endpoints that create new objects on every call so retrying them is only safe when Consul surely didn't apply them
+var retryCreatePaths = map[string]bool{
+	"/v1/acl/auth-method":  true,
+	"/v1/acl/binding-rule": true,
+	"/v1/acl/bootstrap":    true,
+	"/v1/acl/create":       true,
+	"/v1/acl/policy":       true,
+	"/v1/acl/role":         true,
+	"/v1/acl/token":        true,
+}
+
+// Messages of responses that Consul sends without applying the request, e.g. during leader elections
+var retryRejectedMessages = []string{
+	"No cluster leader",
+	"No path to datacenter",
+}
+
+// retryTransport retries requests that failed due to transient issues such as restarts of Consul servers or leader
+// elections. Reads, updates and deletes are retried on any transient failure while requests creating new objects are
+// only retried when it's known that Consul rejected them so that retries never produce duplicates.
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	maxWait     time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	idempotent := isIdempotentRequest(req)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, errors.New("cannot retry a request with a body that cannot be re-read")
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+
+		retry, reason := shouldRetry(resp, err, idempotent)
+		if !retry || attempt >= t.maxAttempts {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		wait := t.backoff(attempt)
+		log.Printf("[WARN] %s %s failed (attempt %d of %d): %s, retrying in %s", req.Method, req.URL.Path, attempt, t.maxAttempts, reason, wait)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Exponential backoff with jitter so that concurrent requests don't hit Consul all at once once it's back
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	wait := t.maxWait
+	if attempt < 32 && retryBaseWait<<uint(attempt-1) < t.maxWait {
+		wait = retryBaseWait << uint(attempt-1)
+	}
+	if wait <= 0 {
+		return 0
+	}
+
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1))
+}
+
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	case http.MethodPut:
+		return !retryCreatePaths[strings.TrimSuffix(req.URL.Path, "/")] && !strings.HasSuffix(req.URL.Path, "/clone")
+	default:
+		return false
+	}
+}
+
+// Returns whether a request should be retried along with the reason, the body of a retried response is preserved
+func shouldRetry(resp *http.Response, err error, idempotent bool) (bool, string) {
+	if err != nil {
+		// nothing was sent if the connection couldn't be established
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true, err.Error()
+		}
+		return idempotent, err.Error()
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true, resp.Status
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent, resp.Status
+	case http.StatusInternalServerError:
+	default:
+		return false, ""
+	}
+
+	body, readErr := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return idempotent, readErr.Error()
+	}
+
+	message := strings.TrimSpace(string(body))
+	for _, rejected := range retryRejectedMessages {
+		if strings.Contains(message, rejected) {
+			return true, message
+		}
+	}
+	for _, unavailable := range unavailableMessages {
+		if strings.Contains(message, unavailable) {
+			return idempotent, message
+		}
+	}
+
+	return false, ""
+}
@@ -0,0 +1,160 @@
+package consulacl_test
+
+import (
+	"encoding/json"
+	"github.com/ashald/terraform-provider-consulacl/consulacl"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+const retryPolicyID = "5d3c1b9a-7e2f-4a6d-9c8b-0e1f2a3b4c5d"
+
+// retryStub is a stand-in for Consul that fails first requests with the given status and message
+type retryStub struct {
+	mutex    sync.Mutex
+	failures int
+	status   int
+	message  string
+	requests []string
+}
+
+func (s *retryStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var policy consul.ACLPolicy
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path+" "+policy.Name)
+
+	if len(s.requests) <= s.failures {
+		http.Error(w, s.message, s.status)
+		return
+	}
+
+	policy.ID = retryPolicyID
+	_ = json.NewEncoder(w).Encode(&policy)
+}
+
+func testRetryClient(t *testing.T, server *httptest.Server, attempts int) *consul.Client {
+	return testConfiguredProviderMeta(t, map[string]interface{}{
+		"address":            strings.TrimPrefix(server.URL, "http://"),
+		"scheme":             "http",
+		"token":              "",
+		"retry_max_attempts": attempts,
+		"retry_max_wait":     "10ms",
+	}).(*consulacl.Meta).Client
+}
+
+func TestRetry(t *testing.T) {
+	cases := []struct {
+		name     string
+		create   bool
+		attempts int
+		stub     *retryStub
+		fails    bool
+		requests int
+	}{
+		{
+			name:     "read during a leader election",
+			attempts: 5,
+			stub:     &retryStub{failures: 2, status: http.StatusInternalServerError, message: "No cluster leader"},
+			requests: 3,
+		},
+		{
+			name:     "read interrupted by a server restart",
+			attempts: 5,
+			stub:     &retryStub{failures: 1, status: http.StatusInternalServerError, message: "rpc error making call: EOF"},
+			requests: 2,
+		},
+		{
+			name:     "read while Consul stays unavailable",
+			attempts: 3,
+			stub:     &retryStub{failures: 10, status: http.StatusServiceUnavailable, message: "Service Unavailable"},
+			fails:    true,
+			requests: 3,
+		},
+		{
+			name:     "read with retries disabled",
+			attempts: 1,
+			stub:     &retryStub{failures: 1, status: http.StatusInternalServerError, message: "No cluster leader"},
+			fails:    true,
+			requests: 1,
+		},
+		{
+			name:     "read of a missing policy",
+			attempts: 5,
+			stub:     &retryStub{failures: 1, status: http.StatusForbidden, message: "ACL not found"},
+			fails:    true,
+			requests: 1,
+		},
+		{
+			name:     "create rejected during a leader election",
+			create:   true,
+			attempts: 5,
+			stub:     &retryStub{failures: 2, status: http.StatusInternalServerError, message: "No cluster leader"},
+			requests: 3,
+		},
+		{
+			name:     "create that might have been applied",
+			create:   true,
+			attempts: 5,
+			stub:     &retryStub{failures: 1, status: http.StatusInternalServerError, message: "rpc error making call: EOF"},
+			fails:    true,
+			requests: 1,
+		},
+		{
+			name:     "invalid create",
+			create:   true,
+			attempts: 5,
+			stub:     &retryStub{failures: 1, status: http.StatusInternalServerError, message: "Invalid Policy: invalid Name"},
+			fails:    true,
+			requests: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.stub)
+			defer server.Close()
+
+			acl := testRetryClient(t, server, tc.attempts).ACL()
+
+			var err error
+			if tc.create {
+				_, _, err = acl.PolicyCreate(&consul.ACLPolicy{Name: "retried"}, nil)
+			} else {
+				_, _, err = acl.PolicyRead(retryPolicyID, nil)
+			}
+
+			if tc.fails && err == nil {
+				t.Fatalf("expected the request to fail")
+			}
+			if !tc.fails && err != nil {
+				t.Fatalf("expected the request to succeed, got: %s", err)
+			}
+			if err != nil && !strings.Contains(err.Error(), tc.stub.message) {
+				t.Fatalf("expected the error of the last attempt to be reported, got: %s", err)
+			}
+
+			if len(tc.stub.requests) != tc.requests {
+				t.Fatalf("expected %d requests, got %d: %v", tc.requests, len(tc.stub.requests), tc.stub.requests)
+			}
+			// bodies have to be sent in full on every attempt
+			for _, request := range tc.stub.requests {
+				if tc.create && request != "PUT /v1/acl/policy retried" {
+					t.Fatalf("unexpected request %q", request)
+				}
+			}
+		})
+	}
+}
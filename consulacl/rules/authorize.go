@@ -0,0 +1,219 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Access levels that can be requested from an Authorizer.
+const (
+	AccessRead  = "read"
+	AccessList  = "list"
+	AccessWrite = "write"
+)
+
+// IntentionResource is a pseudo-resource to query permissions on intentions of a service.
+// They are governed by the `intentions` field of service rules.
+const IntentionResource = "intention"
+
+// When the same target is given different policies the one with the highest precedence wins
+var policyPrecedence = map[string]int{
+	"":          0,
+	PolicyRead:  1,
+	PolicyList:  2,
+	PolicyWrite: 3,
+	PolicyDeny:  4,
+}
+
+// Consul falls back to the policy of another singleton when one is not defined
+var singletonFallbacks = map[string]string{
+	"mesh":    "operator",
+	"peering": "mesh",
+}
+
+// Merge combines several collections of rules, e.g. coming from different policies, into one.
+// Rules for the same target are merged separately for policy and intentions: deny wins, then write, list and read.
+func Merge(collections ...Rules) Rules {
+	var targets []string
+	merged := make(map[string]Rule)
+
+	for _, collection := range collections {
+		for _, rule := range collection {
+			target := rule.Target()
+			existing, ok := merged[target]
+			if !ok {
+				targets = append(targets, target)
+				merged[target] = rule
+				continue
+			}
+			existing.Policy = strongerPolicy(existing.Policy, rule.Policy)
+			existing.Intentions = strongerPolicy(existing.Intentions, rule.Intentions)
+			merged[target] = existing
+		}
+	}
+
+	result := make(Rules, 0, len(targets))
+	for _, target := range targets {
+		result = append(result, merged[target])
+	}
+
+	return result.Sorted()
+}
+
+func strongerPolicy(a, b string) string {
+	if policyPrecedence[b] > policyPrecedence[a] {
+		return b
+	}
+	return a
+}
+
+// ServiceIdentity returns the rules of the synthetic policy Consul generates for a service identity in the given
+// datacenter. Identities limited to other datacenters grant nothing, an empty datacenter stands for an unknown one
+// in which case the limitation cannot be checked and the identity is assumed to apply.
+func ServiceIdentity(service string, datacenters []string, datacenter string) Rules {
+	if datacenter != "" && len(datacenters) > 0 && !contains(datacenters, datacenter) {
+		return nil
+	}
+
+	return Rules{
+		{Resource: "node", Segment: "", Prefix: true, Policy: PolicyRead},
+		{Resource: "service", Segment: service, Policy: PolicyWrite},
+		{Resource: "service", Segment: service + "-sidecar-proxy", Policy: PolicyWrite},
+		{Resource: "service", Segment: "", Prefix: true, Policy: PolicyRead},
+	}
+}
+
+// Consul implies intentions of service rules that don't set them explicitly: read for read and write policies,
+// deny otherwise. That happens for every policy before it's merged with others.
+func impliedIntentions(collection Rules) Rules {
+	result := make(Rules, len(collection))
+	copy(result, collection)
+
+	for i := range result {
+		rule := &result[i]
+		if rule.Resource != "service" || rule.Policy == "" || rule.Intentions != "" {
+			continue
+		}
+		if rule.Policy == PolicyRead || rule.Policy == PolicyWrite {
+			rule.Intentions = PolicyRead
+		} else {
+			rule.Intentions = PolicyDeny
+		}
+	}
+
+	return result
+}
+
+// Decision is the outcome of an authorization query.
+type Decision struct {
+	Allowed bool
+	// Rule is the rule that made the decision, nil if the default policy was applied.
+	Rule *Rule
+}
+
+// Authorizer answers authorization queries the way Consul does for a token linked to a set of rules:
+// an exact match takes precedence over the longest prefix match, and the default policy applies if nothing matches.
+type Authorizer struct {
+	rules Rules
+	// same rules but with intentions implied by service policies
+	intentions   Rules
+	defaultAllow bool
+}
+
+// NewAuthorizer creates an Authorizer for rules merged from all the given collections.
+func NewAuthorizer(defaultAllow bool, collections ...Rules) *Authorizer {
+	implied := make([]Rules, 0, len(collections))
+	for _, collection := range collections {
+		implied = append(implied, impliedIntentions(collection))
+	}
+
+	return &Authorizer{
+		rules:        Merge(collections...),
+		intentions:   Merge(implied...),
+		defaultAllow: defaultAllow,
+	}
+}
+
+// Authorize tells whether the access to the given resource (and a segment for resources scoped to names) is allowed.
+func (a *Authorizer) Authorize(resource, segment, access string) (Decision, error) {
+	switch access {
+	case AccessRead, AccessWrite:
+	case AccessList:
+		if resource != "key" {
+			return Decision{}, fmt.Errorf("%q access is only supported for keys", access)
+		}
+	default:
+		return Decision{}, fmt.Errorf("unknown access %q", access)
+	}
+
+	var rule *Rule
+	switch {
+	case resource == IntentionResource:
+		rule = lookupPrefixed(a.intentions, "service", segment, func(r Rule) string { return r.Intentions })
+	case IsPrefixed(resource):
+		rule = lookupPrefixed(a.rules, resource, segment, func(r Rule) string { return r.Policy })
+	case IsSingleton(resource):
+		if segment != "" {
+			return Decision{}, fmt.Errorf("%q cannot be scoped to a name", resource)
+		}
+		rule = a.lookupSingleton(resource)
+	default:
+		return Decision{}, fmt.Errorf("unknown resource %q", resource)
+	}
+
+	if rule == nil {
+		return Decision{Allowed: a.defaultAllow}, nil
+	}
+
+	policy := rule.Policy
+	if resource == IntentionResource {
+		policy = rule.Intentions
+	}
+
+	return Decision{Allowed: grants(policy, access), Rule: rule}, nil
+}
+
+func lookupPrefixed(collection Rules, resource, segment string, policy func(Rule) string) *Rule {
+	var longest *Rule
+
+	for i := range collection {
+		rule := &collection[i]
+		if rule.Resource != resource || policy(*rule) == "" {
+			continue
+		}
+		if !rule.Prefix && rule.Segment == segment {
+			return rule
+		}
+		if rule.Prefix && strings.HasPrefix(segment, rule.Segment) {
+			if longest == nil || len(rule.Segment) > len(longest.Segment) {
+				longest = rule
+			}
+		}
+	}
+
+	return longest
+}
+
+func (a *Authorizer) lookupSingleton(resource string) *Rule {
+	for i := range a.rules {
+		if a.rules[i].Resource == resource && a.rules[i].Policy != "" {
+			return &a.rules[i]
+		}
+	}
+	if fallback, ok := singletonFallbacks[resource]; ok {
+		return a.lookupSingleton(fallback)
+	}
+	return nil
+}
+
+func grants(policy, access string) bool {
+	switch policy {
+	case PolicyWrite:
+		return true
+	case PolicyList:
+		return access == AccessList || access == AccessRead
+	case PolicyRead:
+		return access == AccessRead
+	}
+	return false
+}
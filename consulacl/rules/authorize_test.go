@@ -0,0 +1,183 @@
+package rules_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	first := rules.Rules{
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "read"},
+		{Resource: "service", Segment: "web", Policy: "write"},
+		{Resource: "operator", Policy: "write"},
+	}
+	second := rules.Rules{
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "list"},
+		{Resource: "service", Segment: "web", Policy: "read", Intentions: "read"},
+		{Resource: "operator", Policy: "deny"},
+	}
+
+	expected := rules.Rules{
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "list"},
+		{Resource: "operator", Policy: "deny"},
+		{Resource: "service", Segment: "web", Policy: "write", Intentions: "read"},
+	}
+
+	if actual := rules.Merge(first, second); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	policy := rules.Rules{
+		{Resource: "key", Segment: "", Prefix: true, Policy: "read"},
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "write"},
+		{Resource: "key", Segment: "app/secrets/", Prefix: true, Policy: "deny"},
+		{Resource: "key", Segment: "app/secrets/public", Policy: "list"},
+		{Resource: "service", Segment: "", Prefix: true, Intentions: "read"},
+		{Resource: "operator", Policy: "read"},
+	}
+	identity := rules.ServiceIdentity("web", nil, "")
+
+	cases := []struct {
+		resource, segment, access string
+		allowed                   bool
+		rule                      *rules.Rule
+	}{
+		{"key", "other", "read", true, &policy[0]},
+		{"key", "other", "write", false, &policy[0]},
+		{"key", "app/config", "write", true, &policy[1]},
+		{"key", "app/secrets/token", "read", false, &policy[2]},
+		{"key", "app/secrets/public", "list", true, &policy[3]},
+		{"key", "app/secrets/public", "write", false, &policy[3]},
+		{"service", "web", "write", true, &identity[1]},
+		{"service", "web-sidecar-proxy", "write", true, &identity[2]},
+		{"service", "db", "write", false, &rules.Rule{Resource: "service", Segment: "", Prefix: true, Policy: "read", Intentions: "read"}},
+		{"intention", "db", "read", true, &rules.Rule{Resource: "service", Segment: "", Prefix: true, Policy: "read", Intentions: "read"}},
+		{"intention", "db", "write", false, &rules.Rule{Resource: "service", Segment: "", Prefix: true, Policy: "read", Intentions: "read"}},
+		{"node", "any", "read", true, &identity[0]},
+		{"mesh", "", "read", true, &policy[5]},
+		{"mesh", "", "write", false, &policy[5]},
+		{"acl", "", "read", false, nil},
+		{"session", "any", "read", false, nil},
+	}
+
+	authorizer := rules.NewAuthorizer(false, policy, identity)
+
+	for _, tc := range cases {
+		decision, err := authorizer.Authorize(tc.resource, tc.segment, tc.access)
+		if err != nil {
+			t.Fatalf("%s %q %s: unexpected error: %s", tc.resource, tc.segment, tc.access, err)
+		}
+		if decision.Allowed != tc.allowed {
+			t.Errorf("%s %q %s: expected allowed=%t, got %t", tc.resource, tc.segment, tc.access, tc.allowed, decision.Allowed)
+		}
+		if !reflect.DeepEqual(decision.Rule, tc.rule) {
+			t.Errorf("%s %q %s: expected rule %#v, got %#v", tc.resource, tc.segment, tc.access, tc.rule, decision.Rule)
+		}
+	}
+
+	permissive := rules.NewAuthorizer(true)
+	if decision, _ := permissive.Authorize("acl", "", "write"); !decision.Allowed || decision.Rule != nil {
+		t.Errorf("expected the default policy to allow access, got %#v", decision)
+	}
+
+	for _, query := range [][3]string{{"foo", "", "read"}, {"key", "", "admin"}, {"service", "web", "list"}, {"operator", "foo", "read"}} {
+		if _, err := authorizer.Authorize(query[0], query[1], query[2]); err == nil {
+			t.Errorf("%v: expected an error", query)
+		}
+	}
+}
+
+func TestAuthorizeImpliedIntentions(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  rules.Rules
+		access  string
+		allowed bool
+		rule    *rules.Rule
+	}{
+		{
+			name:    "read policy",
+			policy:  rules.Rules{{Resource: "service", Segment: "web", Policy: "read"}},
+			access:  "read",
+			allowed: true,
+			rule:    &rules.Rule{Resource: "service", Segment: "web", Policy: "read", Intentions: "read"},
+		},
+		{
+			name:    "write policy",
+			policy:  rules.Rules{{Resource: "service", Segment: "", Prefix: true, Policy: "write"}},
+			access:  "write",
+			allowed: false,
+			rule:    &rules.Rule{Resource: "service", Segment: "", Prefix: true, Policy: "write", Intentions: "read"},
+		},
+		{
+			name:    "deny policy",
+			policy:  rules.Rules{{Resource: "service", Segment: "web", Policy: "deny"}},
+			access:  "read",
+			allowed: false,
+			rule:    &rules.Rule{Resource: "service", Segment: "web", Policy: "deny", Intentions: "deny"},
+		},
+		{
+			name:    "explicit intentions",
+			policy:  rules.Rules{{Resource: "service", Segment: "web", Policy: "read", Intentions: "write"}},
+			access:  "write",
+			allowed: true,
+			rule:    &rules.Rule{Resource: "service", Segment: "web", Policy: "read", Intentions: "write"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// the default policy must not be what makes the decision
+			decision, err := rules.NewAuthorizer(!tc.allowed, tc.policy).Authorize(rules.IntentionResource, "web", tc.access)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if decision.Allowed != tc.allowed {
+				t.Errorf("expected allowed=%t, got %t", tc.allowed, decision.Allowed)
+			}
+			if !reflect.DeepEqual(decision.Rule, tc.rule) {
+				t.Errorf("expected rule %#v, got %#v", tc.rule, decision.Rule)
+			}
+		})
+	}
+
+	// implied intentions are evaluated for every policy before they're merged, so denied services stay denied
+	authorizer := rules.NewAuthorizer(false,
+		rules.Rules{{Resource: "service", Segment: "web", Policy: "deny"}},
+		rules.Rules{{Resource: "service", Segment: "web", Intentions: "write"}},
+	)
+	if decision, _ := authorizer.Authorize(rules.IntentionResource, "web", "read"); decision.Allowed {
+		t.Errorf("expected intentions of a denied service to be denied, got %#v", decision)
+	}
+}
+
+func TestServiceIdentityDatacenters(t *testing.T) {
+	cases := []struct {
+		name        string
+		datacenters []string
+		datacenter  string
+		applies     bool
+	}{
+		{name: "all datacenters", datacenters: nil, datacenter: "dc1", applies: true},
+		{name: "listed datacenter", datacenters: []string{"dc1", "dc2"}, datacenter: "dc2", applies: true},
+		{name: "other datacenter", datacenters: []string{"dc1"}, datacenter: "dc2", applies: false},
+		{name: "unknown datacenter", datacenters: []string{"dc1"}, datacenter: "", applies: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			authorizer := rules.NewAuthorizer(false, rules.ServiceIdentity("web", tc.datacenters, tc.datacenter))
+
+			decision, err := authorizer.Authorize("service", "web", "write")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if decision.Allowed != tc.applies {
+				t.Errorf("expected allowed=%t, got %t", tc.applies, decision.Allowed)
+			}
+		})
+	}
+}
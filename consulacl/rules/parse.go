@@ -0,0 +1,209 @@
+package rules
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+)
+
+const (
+	fieldPolicy     = "policy"
+	fieldIntentions = "intentions"
+)
+
+// Parse reads a rules document written either in HCL or in JSON. The rules are returned in the document order.
+func Parse(text string) (Rules, error) {
+	file, err := hcl.ParseString(text)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := file.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("rules must be a set of definitions")
+	}
+
+	var result Rules
+	for _, item := range list.Items {
+		keys, err := itemKeys(item)
+		if err != nil {
+			return nil, err
+		}
+		rules, err := parseDefinition(keys, item.Val)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rules...)
+	}
+
+	return result, nil
+}
+
+// Parses any of the shapes a definition can take after being processed by HCL:
+//
+//	operator = "read"                      keys: [operator]        value: literal
+//	key_prefix "foo" { policy = "read" }   keys: [key_prefix foo]  value: object
+//	{"key_prefix": {"foo": {...}}}         keys: [key_prefix]      value: object with nested definitions
+//	{"key_prefix": [{"foo": {...}}]}       keys: [key_prefix]      value: list of the above
+func parseDefinition(keys []string, value ast.Node) (Rules, error) {
+	resource, prefix := splitKeyword(keys[0])
+
+	if IsSingleton(resource) && !prefix {
+		if len(keys) > 1 {
+			return nil, fmt.Errorf("%s: %q cannot be scoped to a name", position(value), keys[0])
+		}
+		if list, ok := value.(*ast.ListType); ok {
+			var result Rules
+			for _, elem := range list.List {
+				rules, err := parseDefinition(keys, elem)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, rules...)
+			}
+			return result, nil
+		}
+
+		policy, err := stringValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q %s", position(value), keys[0], err)
+		}
+		rule := Rule{Resource: resource, Policy: policy}
+		return Rules{rule}, validateAt(rule, value)
+	}
+
+	if !IsPrefixed(resource) {
+		return nil, fmt.Errorf("%s: unknown resource %q", position(value), keys[0])
+	}
+
+	if len(keys) > 2 {
+		return nil, fmt.Errorf("%s: %q must be scoped to a single name, got %q", position(value), keys[0], keys[1:])
+	}
+
+	switch value := value.(type) {
+	case *ast.ListType:
+		var result Rules
+		for _, elem := range value.List {
+			rules, err := parseDefinition(keys, elem)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, rules...)
+		}
+		return result, nil
+	case *ast.ObjectType:
+		if len(keys) == 1 {
+			// The segment is one level deeper, as in JSON documents
+			var result Rules
+			for _, item := range value.List.Items {
+				nested, err := itemKeys(item)
+				if err != nil {
+					return nil, err
+				}
+				rules, err := parseDefinition(append([]string{keys[0]}, nested...), item.Val)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, rules...)
+			}
+			return result, nil
+		}
+
+		rule := Rule{Resource: resource, Segment: keys[1], Prefix: prefix}
+		if err := parseBody(&rule, value); err != nil {
+			return nil, err
+		}
+		return Rules{rule}, validateAt(rule, value)
+	}
+
+	return nil, fmt.Errorf("%s: %q must be a block", position(value), keys[0])
+}
+
+func parseBody(rule *Rule, body *ast.ObjectType) error {
+	seen := make(map[string]bool)
+
+	for _, item := range body.List.Items {
+		keys, err := itemKeys(item)
+		if err != nil {
+			return err
+		}
+		if len(keys) != 1 {
+			return fmt.Errorf("%s: unexpected block %q in %s %q", position(item), keys, rule.Keyword(), rule.Segment)
+		}
+
+		field := keys[0]
+		if seen[field] {
+			return fmt.Errorf("%s: %q is defined more than once in %s %q", position(item), field, rule.Keyword(), rule.Segment)
+		}
+		seen[field] = true
+
+		value, err := stringValue(item.Val)
+		if err != nil {
+			return fmt.Errorf("%s: %q %s", position(item), field, err)
+		}
+
+		switch field {
+		case fieldPolicy:
+			rule.Policy = value
+		case fieldIntentions:
+			rule.Intentions = value
+		default:
+			return fmt.Errorf("%s: unexpected field %q in %s %q", position(item), field, rule.Keyword(), rule.Segment)
+		}
+	}
+
+	return nil
+}
+
+func validateAt(rule Rule, node ast.Node) error {
+	if err := rule.Validate(); err != nil {
+		return fmt.Errorf("%s: %s", position(node), err)
+	}
+	return nil
+}
+
+func itemKeys(item *ast.ObjectItem) ([]string, error) {
+	var result []string
+	for _, key := range item.Keys {
+		value, err := tokenString(key.Token)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid key: %s", key.Token.Pos, err)
+		}
+		result = append(result, value)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%s: definition without a name", position(item))
+	}
+	return result, nil
+}
+
+func stringValue(node ast.Node) (string, error) {
+	literal, ok := node.(*ast.LiteralType)
+	if !ok {
+		return "", fmt.Errorf("must be a string")
+	}
+	return tokenString(literal.Token)
+}
+
+func tokenString(tok token.Token) (result string, err error) {
+	switch tok.Type {
+	case token.STRING, token.IDENT, token.HEREDOC:
+	default:
+		return "", fmt.Errorf("must be a string, got %s", tok.Type)
+	}
+
+	// HCL panics on values it cannot unquote
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return tok.Value().(string), nil
+}
+
+func position(node ast.Node) string {
+	pos := node.Pos()
+	return fmt.Sprintf("line %d, column %d", pos.Line, pos.Column)
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HCL renders the rules in the canonical HCL form: one definition per line, in the canonical order.
+func (rules Rules) HCL() string {
+	var buf strings.Builder
+
+	for _, rule := range rules.Sorted() {
+		if IsSingleton(rule.Resource) {
+			fmt.Fprintf(&buf, "%s = %s\n", rule.Keyword(), strconv.Quote(rule.Policy))
+			continue
+		}
+
+		var fields []string
+		if rule.Policy != "" {
+			fields = append(fields, fmt.Sprintf("%s = %s", fieldPolicy, strconv.Quote(rule.Policy)))
+		}
+		if rule.Intentions != "" {
+			fields = append(fields, fmt.Sprintf("%s = %s", fieldIntentions, strconv.Quote(rule.Intentions)))
+		}
+		fmt.Fprintf(&buf, "%s %s { %s }\n", rule.Keyword(), strconv.Quote(rule.Segment), strings.Join(fields, ", "))
+	}
+
+	return buf.String()
+}
+
+// JSON renders the rules in the canonical JSON form. Definitions that occur more than once are rendered as lists.
+func (rules Rules) JSON() (string, error) {
+	keywords := make(map[string][]Rule)
+	for _, rule := range rules.Sorted() {
+		keywords[rule.Keyword()] = append(keywords[rule.Keyword()], rule)
+	}
+
+	document := make(map[string]interface{})
+	for keyword, group := range keywords {
+		if IsSingleton(group[0].Resource) {
+			if len(group) == 1 {
+				document[keyword] = group[0].Policy
+				continue
+			}
+			var policies []string
+			for _, rule := range group {
+				policies = append(policies, rule.Policy)
+			}
+			document[keyword] = policies
+			continue
+		}
+
+		segments := make(map[string]interface{})
+		duplicated := false
+		for _, rule := range group {
+			if _, ok := segments[rule.Segment]; ok {
+				duplicated = true
+			}
+			segments[rule.Segment] = jsonBody(rule)
+		}
+
+		if !duplicated {
+			document[keyword] = segments
+			continue
+		}
+
+		var definitions []interface{}
+		for _, rule := range group {
+			definitions = append(definitions, map[string]interface{}{rule.Segment: jsonBody(rule)})
+		}
+		document[keyword] = definitions
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func jsonBody(rule Rule) map[string]string {
+	body := make(map[string]string)
+	if rule.Policy != "" {
+		body[fieldPolicy] = rule.Policy
+	}
+	if rule.Intentions != "" {
+		body[fieldIntentions] = rule.Intentions
+	}
+	return body
+}
@@ -0,0 +1,230 @@
+// Package rules implements the Consul ACL rules language: an AST, a parser that accepts both HCL and JSON flavours
+// and a canonical printer for either of them. Printing a parsed document and parsing it back yields the same rules.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PrefixSuffix turns a resource into its prefix-matching form, e.g. `key` into `key_prefix`.
+const PrefixSuffix = "_prefix"
+
+// Policies that can be granted by a rule.
+const (
+	PolicyRead  = "read"
+	PolicyWrite = "write"
+	PolicyDeny  = "deny"
+	PolicyList  = "list"
+)
+
+// PrefixedResources are resources whose rules are scoped to a name (exact match) or a name prefix.
+var PrefixedResources = []string{"agent", "event", "key", "node", "query", "service", "session"}
+
+// SingletonResources are resources with a single cluster-wide policy, e.g. `operator = "read"`.
+var SingletonResources = []string{"acl", "keyring", "mesh", "operator", "peering"}
+
+// Rule is a single statement of an ACL rules document.
+type Rule struct {
+	// Resource is the bare resource name, e.g. `key` or `operator`, without the `_prefix` suffix.
+	Resource string
+	// Segment is the name or the name prefix the rule applies to. Always empty for singleton resources.
+	Segment string
+	// Prefix is set when the rule was written in the `<resource>_prefix` form.
+	// Note that legacy (pre-1.4) ACLs treat both forms as prefix matches.
+	Prefix bool
+	// Policy is one of read, write, deny or list (the latter only for keys).
+	Policy string
+	// Intentions is the policy for service intentions, only valid for services.
+	Intentions string
+}
+
+// Rules is an ordered collection of rules as they appear in a document.
+type Rules []Rule
+
+// IsPrefixed tells whether the resource is scoped to a name or a name prefix.
+func IsPrefixed(resource string) bool {
+	return contains(PrefixedResources, resource)
+}
+
+// IsSingleton tells whether the resource has a single cluster-wide policy.
+func IsSingleton(resource string) bool {
+	return contains(SingletonResources, resource)
+}
+
+// Keyword is the identifier used for the rule in a document, e.g. `key_prefix`.
+func (r Rule) Keyword() string {
+	if r.Prefix {
+		return r.Resource + PrefixSuffix
+	}
+	return r.Resource
+}
+
+// Target identifies what the rule applies to. Rules with the same target override each other.
+func (r Rule) Target() string {
+	if IsSingleton(r.Resource) {
+		return r.Keyword()
+	}
+	return fmt.Sprintf("%s %q", r.Keyword(), r.Segment)
+}
+
+// Validate checks that the rule can be understood by Consul.
+func (r Rule) Validate() error {
+	switch {
+	case IsSingleton(r.Resource):
+		if r.Prefix || r.Segment != "" {
+			return fmt.Errorf("%q cannot be scoped to a name", r.Resource)
+		}
+		if r.Intentions != "" {
+			return fmt.Errorf("%q does not support intentions", r.Resource)
+		}
+		return validatePolicy(r.Resource, r.Policy, false)
+	case IsPrefixed(r.Resource):
+		if r.Intentions != "" && r.Resource != "service" {
+			return fmt.Errorf("%q does not support intentions", r.Keyword())
+		}
+		if r.Policy == "" && r.Intentions == "" {
+			return fmt.Errorf("%s %q must have a policy", r.Keyword(), r.Segment)
+		}
+		if r.Policy != "" {
+			if err := validatePolicy(r.Keyword(), r.Policy, r.Resource == "key"); err != nil {
+				return err
+			}
+		}
+		if r.Intentions != "" {
+			return validatePolicy(r.Keyword()+" intentions", r.Intentions, false)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown resource %q", r.Resource)
+}
+
+func validatePolicy(context, policy string, allowList bool) error {
+	switch policy {
+	case PolicyRead, PolicyWrite, PolicyDeny:
+		return nil
+	case PolicyList:
+		if allowList {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid policy %q for %s", policy, context)
+}
+
+// Sorted returns a copy of the rules in the canonical order: by keyword, then by segment.
+func (rules Rules) Sorted() Rules {
+	result := make(Rules, len(rules))
+	copy(result, rules)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].less(result[j])
+	})
+	return result
+}
+
+func (r Rule) less(other Rule) bool {
+	if a, b := r.Keyword(), other.Keyword(); a != b {
+		return a < b
+	}
+	if r.Segment != other.Segment {
+		return r.Segment < other.Segment
+	}
+	if r.Policy != other.Policy {
+		return r.Policy < other.Policy
+	}
+	return r.Intentions < other.Intentions
+}
+
+// Normalize returns the rules in the canonical order without duplicates.
+// It fails if the same target is given different policies.
+func (rules Rules) Normalize() (Rules, error) {
+	var result Rules
+	seen := make(map[string]Rule)
+
+	for _, rule := range rules.Sorted() {
+		if previous, ok := seen[rule.Target()]; ok {
+			if previous != rule {
+				return nil, fmt.Errorf("conflicting definitions of %s", rule.Target())
+			}
+			continue
+		}
+		seen[rule.Target()] = rule
+		result = append(result, rule)
+	}
+
+	return result, nil
+}
+
+// Override returns the rules with every target defined in overrides replaced by its new definition.
+func (rules Rules) Override(overrides Rules) Rules {
+	replaced := make(map[string]bool)
+	for _, rule := range overrides {
+		replaced[rule.Target()] = true
+	}
+
+	var result Rules
+	for _, rule := range rules {
+		if !replaced[rule.Target()] {
+			result = append(result, rule)
+		}
+	}
+
+	return append(result, overrides...).Sorted()
+}
+
+// Equal tells whether both collections contain the same rules regardless of their order.
+func (rules Rules) Equal(other Rules) bool {
+	if len(rules) != len(other) {
+		return false
+	}
+	a, b := rules.Sorted(), other.Sorted()
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equivalent tells whether both collections grant the same permissions, i.e. contain the same distinct rules.
+func (rules Rules) Equivalent(other Rules) bool {
+	return rules.distinct().equal(other.distinct())
+}
+
+type ruleSet map[Rule]bool
+
+func (rules Rules) distinct() ruleSet {
+	result := make(ruleSet)
+	for _, rule := range rules {
+		result[rule] = true
+	}
+	return result
+}
+
+func (set ruleSet) equal(other ruleSet) bool {
+	if len(set) != len(other) {
+		return false
+	}
+	for rule := range set {
+		if !other[rule] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, elem := range list {
+		if elem == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitKeyword(keyword string) (string, bool) {
+	if strings.HasSuffix(keyword, PrefixSuffix) {
+		return strings.TrimSuffix(keyword, PrefixSuffix), true
+	}
+	return keyword, false
+}
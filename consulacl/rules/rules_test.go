@@ -0,0 +1,286 @@
+package rules_test
+
+import (
+	"github.com/ashald/terraform-provider-consulacl/consulacl/rules"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		text     string
+		expected rules.Rules
+	}{
+		"empty": {
+			text:     "",
+			expected: nil,
+		},
+		"singletons": {
+			text: `
+				acl = "write"
+				keyring = "deny"
+				operator = "read"
+			`,
+			expected: rules.Rules{
+				{Resource: "acl", Policy: "write"},
+				{Resource: "keyring", Policy: "deny"},
+				{Resource: "operator", Policy: "read"},
+			},
+		},
+		"exact and prefix": {
+			text: `
+				key "Foo/Bar" { policy = "write" }
+				key_prefix "Foo/" { policy = "list" }
+				service "web" {
+					policy = "read"
+					intentions = "write"
+				}
+				service_prefix "" { intentions = "read" }
+			`,
+			expected: rules.Rules{
+				{Resource: "key", Segment: "Foo/Bar", Policy: "write"},
+				{Resource: "key", Segment: "Foo/", Prefix: true, Policy: "list"},
+				{Resource: "service", Segment: "web", Policy: "read", Intentions: "write"},
+				{Resource: "service", Segment: "", Prefix: true, Intentions: "read"},
+			},
+		},
+		"escaped segment": {
+			text: `node "say \"hi\"\\" { policy = "read" }`,
+			expected: rules.Rules{
+				{Resource: "node", Segment: `say "hi"\`, Policy: "read"},
+			},
+		},
+		"json": {
+			text: `{
+				"operator": "read",
+				"key_prefix": {"foo/": {"policy": "write"}, "bar/": {"policy": "deny"}},
+				"service": [{"web": {"policy": "read"}}, {"db": {"policy": "write", "intentions": "read"}}]
+			}`,
+			expected: rules.Rules{
+				{Resource: "operator", Policy: "read"},
+				{Resource: "key", Segment: "foo/", Prefix: true, Policy: "write"},
+				{Resource: "key", Segment: "bar/", Prefix: true, Policy: "deny"},
+				{Resource: "service", Segment: "web", Policy: "read"},
+				{Resource: "service", Segment: "db", Policy: "write", Intentions: "read"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := rules.Parse(tc.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !tc.expected.Equal(actual) {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]struct {
+		text     string
+		expected string
+	}{
+		"syntax":              {`key "foo" {`, "expected"},
+		"unknown resource":    {`foo "bar" { policy = "read" }`, `unknown resource "foo"`},
+		"scoped singleton":    {`operator "foo" { policy = "read" }`, `"operator" cannot be scoped to a name`},
+		"unscoped prefixed":   {`key = "read"`, `"key" must be a block`},
+		"singleton prefix":    {`operator_prefix "" { policy = "read" }`, `unknown resource "operator_prefix"`},
+		"invalid policy":      {`key "foo" { policy = "admin" }`, `invalid policy "admin"`},
+		"list outside keys":   {`service "foo" { policy = "list" }`, `invalid policy "list"`},
+		"missing policy":      {`key "foo" {}`, "must have a policy"},
+		"unexpected field":    {`key "foo" { policy = "read" sentinel = "bar" }`, `unexpected field "sentinel"`},
+		"invalid intentions":  {`key "foo" { policy = "read" intentions = "read" }`, "does not support intentions"},
+		"duplicate field":     {`key "foo" { policy = "read" policy = "write" }`, "defined more than once"},
+		"non-string policy":   {`operator = 1`, "must be a string"},
+		"too many labels":     {`key "foo" "bar" { policy = "read" }`, "must be scoped to a single name"},
+		"non-object in block": {`key "foo" = "read"`, "expected"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := rules.Parse(tc.text)
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tc.expected)
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected an error containing %q, got: %s", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	input := rules.Rules{
+		{Resource: "service", Segment: "web", Policy: "read", Intentions: "write"},
+		{Resource: "operator", Policy: "read"},
+		{Resource: "key", Segment: "Foo/", Prefix: true, Policy: "write"},
+		{Resource: "key", Segment: `"quoted"`, Policy: "deny"},
+	}
+
+	expectedHCL := strings.Join([]string{
+		`key "\"quoted\"" { policy = "deny" }`,
+		`key_prefix "Foo/" { policy = "write" }`,
+		`operator = "read"`,
+		`service "web" { policy = "read", intentions = "write" }`,
+		``,
+	}, "\n")
+
+	if actual := input.HCL(); actual != expectedHCL {
+		t.Fatalf("expected HCL:\n%s\ngot:\n%s", expectedHCL, actual)
+	}
+
+	expectedJSON := strings.Join([]string{
+		`{`,
+		`  "key": {`,
+		`    "\"quoted\"": {`,
+		`      "policy": "deny"`,
+		`    }`,
+		`  },`,
+		`  "key_prefix": {`,
+		`    "Foo/": {`,
+		`      "policy": "write"`,
+		`    }`,
+		`  },`,
+		`  "operator": "read",`,
+		`  "service": {`,
+		`    "web": {`,
+		`      "intentions": "write",`,
+		`      "policy": "read"`,
+		`    }`,
+		`  }`,
+		`}`,
+		``,
+	}, "\n")
+
+	actualJSON, err := input.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if actualJSON != expectedJSON {
+		t.Fatalf("expected JSON:\n%s\ngot:\n%s", expectedJSON, actualJSON)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	input := rules.Rules{
+		{Resource: "acl", Policy: "read"},
+		{Resource: "agent", Segment: "", Prefix: true, Policy: "read"},
+		{Resource: "event", Segment: "deploy", Policy: "write"},
+		{Resource: "key", Segment: "UPPER/lower", Policy: "list"},
+		{Resource: "key", Segment: "UPPER/lower", Prefix: true, Policy: "deny"},
+		{Resource: "key", Segment: "dup", Policy: "read"},
+		{Resource: "key", Segment: "dup", Policy: "write"},
+		{Resource: "mesh", Policy: "write"},
+		{Resource: "node", Segment: "back\\slash \"quote\"\ttab\nnewline ünïcødé", Policy: "read"},
+		{Resource: "operator", Policy: "read"},
+		{Resource: "operator", Policy: "write"},
+		{Resource: "peering", Policy: "read"},
+		{Resource: "query", Segment: "q", Prefix: true, Policy: "write"},
+		{Resource: "service", Segment: "web", Intentions: "deny"},
+		{Resource: "session", Segment: "s", Policy: "deny"},
+	}
+
+	hclRules, err := rules.Parse(input.HCL())
+	if err != nil {
+		t.Fatalf("cannot parse HCL: %s\n%s", err, input.HCL())
+	}
+	if !reflect.DeepEqual(input.Sorted(), hclRules) {
+		t.Fatalf("HCL round trip mismatch:\nexpected %#v\ngot      %#v", input.Sorted(), hclRules)
+	}
+
+	text, err := input.JSON()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	jsonRules, err := rules.Parse(text)
+	if err != nil {
+		t.Fatalf("cannot parse JSON: %s\n%s", err, text)
+	}
+	if !input.Equal(jsonRules) {
+		t.Fatalf("JSON round trip mismatch:\nexpected %#v\ngot      %#v", input.Sorted(), jsonRules.Sorted())
+	}
+
+	if hclRules.HCL() != input.HCL() {
+		t.Fatalf("HCL is not canonical:\n%s\n%s", hclRules.HCL(), input.HCL())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	input := rules.Rules{
+		{Resource: "service", Segment: "web", Policy: "read"},
+		{Resource: "operator", Policy: "read"},
+		{Resource: "service", Segment: "web", Policy: "read"},
+		{Resource: "service", Segment: "web", Prefix: true, Policy: "write"},
+	}
+
+	expected := rules.Rules{
+		{Resource: "operator", Policy: "read"},
+		{Resource: "service", Segment: "web", Policy: "read"},
+		{Resource: "service", Segment: "web", Prefix: true, Policy: "write"},
+	}
+
+	actual, err := input.Normalize()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	conflicting := append(input, rules.Rule{Resource: "operator", Policy: "write"})
+	if _, err := conflicting.Normalize(); err == nil || !strings.Contains(err.Error(), "conflicting definitions of operator") {
+		t.Fatalf("expected a conflict error, got: %v", err)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	base := rules.Rules{
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "read"},
+		{Resource: "key", Segment: "app/config", Policy: "read"},
+		{Resource: "operator", Policy: "read"},
+	}
+	overrides := rules.Rules{
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "write"},
+		{Resource: "operator", Policy: "deny"},
+	}
+
+	expected := rules.Rules{
+		{Resource: "key", Segment: "app/config", Policy: "read"},
+		{Resource: "key", Segment: "app/", Prefix: true, Policy: "write"},
+		{Resource: "operator", Policy: "deny"},
+	}
+
+	if actual := base.Override(overrides); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	hcl, err := rules.Parse(`
+		operator = "read"
+		key "foo" { policy = "read" }
+		key "foo" { policy = "read" }
+	`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	json, err := rules.Parse(`{"key": {"foo": {"policy": "read"}}, "operator": "read"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !hcl.Equivalent(json) {
+		t.Fatalf("expected %#v to be equivalent to %#v", hcl, json)
+	}
+
+	prefixed := rules.Rules{{Resource: "key", Segment: "foo", Prefix: true, Policy: "read"}, {Resource: "operator", Policy: "read"}}
+	if hcl.Equivalent(prefixed) {
+		t.Fatalf("expected %#v not to be equivalent to %#v", hcl, prefixed)
+	}
+}
@@ -0,0 +1,287 @@
+package consulacl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Binding rule selectors use the boolean expression language of github.com/hashicorp/go-bexpr, e.g.:
+//   serviceaccount.namespace == default and serviceaccount.name != vault
+// The parser below only checks the syntax and collects the selectors, it doesn't evaluate expressions.
+
+type selectorTokenKind int
+
+const (
+	selectorTokenEOF selectorTokenKind = iota
+	selectorTokenIdentifier
+	selectorTokenString
+	selectorTokenNumber
+	selectorTokenOperator
+	selectorTokenLeftParen
+	selectorTokenRightParen
+	selectorTokenDot
+)
+
+type selectorToken struct {
+	kind     selectorTokenKind
+	value    string
+	position int
+}
+
+var selectorKeywords = map[string]bool{
+	"and": true, "or": true, "not": true, "in": true, "contains": true, "is": true, "empty": true, "matches": true,
+}
+
+func tokenizeSelector(expression string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, selectorToken{selectorTokenLeftParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, selectorToken{selectorTokenRightParen, ")", i})
+			i++
+		case r == '.':
+			tokens = append(tokens, selectorToken{selectorTokenDot, ".", i})
+			i++
+		case r == '=' || r == '!':
+			if i+1 >= len(runes) || runes[i+1] != '=' {
+				return nil, fmt.Errorf("unexpected character %q at position %d, expected '==' or '!='", r, i)
+			}
+			tokens = append(tokens, selectorToken{selectorTokenOperator, string(runes[i : i+2]), i})
+			i += 2
+		case r == '"' || r == '`':
+			start := i
+			var value strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == r {
+					closed = true
+					i++
+					break
+				}
+				if r == '"' && runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string starting at position %d", start)
+			}
+			tokens = append(tokens, selectorToken{selectorTokenString, value.String(), start})
+		case unicode.IsDigit(r) || r == '-' || r == '+':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, selectorToken{selectorTokenNumber, string(runes[start:i]), start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, selectorToken{selectorTokenIdentifier, string(runes[start:i]), start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, selectorToken{selectorTokenEOF, "", len(runes)}), nil
+}
+
+type selectorParser struct {
+	tokens    []selectorToken
+	position  int
+	selectors []string
+}
+
+// Parses a binding rule selector and returns all selectors (dotted field paths) referenced by it
+func parseSelector(expression string) ([]string, error) {
+	tokens, err := tokenizeSelector(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := &selectorParser{tokens: tokens}
+	if err := parser.parseOr(); err != nil {
+		return nil, err
+	}
+
+	if token := parser.peek(); token.kind != selectorTokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", token.value, token.position)
+	}
+
+	return parser.selectors, nil
+}
+
+func (p *selectorParser) peek() selectorToken {
+	return p.tokens[p.position]
+}
+
+func (p *selectorParser) next() selectorToken {
+	token := p.tokens[p.position]
+	if token.kind != selectorTokenEOF {
+		p.position++
+	}
+	return token
+}
+
+func (p *selectorParser) isKeyword(keyword string) bool {
+	token := p.peek()
+	return token.kind == selectorTokenIdentifier && token.value == keyword
+}
+
+func (p *selectorParser) expectKeyword(keyword string) error {
+	token := p.next()
+	if token.kind != selectorTokenIdentifier || token.value != keyword {
+		return fmt.Errorf("expected %q at position %d, got %q", keyword, token.position, token.value)
+	}
+	return nil
+}
+
+func (p *selectorParser) parseOr() error {
+	if err := p.parseAnd(); err != nil {
+		return err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		if err := p.parseAnd(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *selectorParser) parseAnd() error {
+	if err := p.parseNot(); err != nil {
+		return err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		if err := p.parseNot(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *selectorParser) parseNot() error {
+	if p.isKeyword("not") {
+		p.next()
+		return p.parseNot()
+	}
+	return p.parsePrimary()
+}
+
+func (p *selectorParser) parsePrimary() error {
+	token := p.peek()
+
+	if token.kind == selectorTokenLeftParen {
+		p.next()
+		if err := p.parseOr(); err != nil {
+			return err
+		}
+		if closing := p.next(); closing.kind != selectorTokenRightParen {
+			return fmt.Errorf("expected ')' at position %d, got %q", closing.position, closing.value)
+		}
+		return nil
+	}
+
+	// <value> [not] in <selector>
+	if token.kind == selectorTokenString || token.kind == selectorTokenNumber {
+		p.next()
+		if p.isKeyword("not") {
+			p.next()
+		}
+		if err := p.expectKeyword("in"); err != nil {
+			return err
+		}
+		return p.parseSelectorPath()
+	}
+
+	if err := p.parseSelectorPath(); err != nil {
+		return err
+	}
+
+	operator := p.next()
+	switch {
+	case operator.kind == selectorTokenOperator:
+		// <selector> ==|!= <value>
+		return p.parseValue()
+	case operator.kind == selectorTokenIdentifier && operator.value == "is":
+		// <selector> is [not] empty
+		if p.isKeyword("not") {
+			p.next()
+		}
+		return p.expectKeyword("empty")
+	case operator.kind == selectorTokenIdentifier && operator.value == "not":
+		// <selector> not contains|matches <value>
+		next := p.next()
+		if next.kind != selectorTokenIdentifier || (next.value != "contains" && next.value != "matches") {
+			return fmt.Errorf("expected 'contains' or 'matches' at position %d, got %q", next.position, next.value)
+		}
+		return p.parseMatchValue(next.value)
+	case operator.kind == selectorTokenIdentifier && (operator.value == "contains" || operator.value == "matches"):
+		// <selector> contains|matches <value>
+		return p.parseMatchValue(operator.value)
+	}
+
+	return fmt.Errorf("expected an operator at position %d, got %q", operator.position, operator.value)
+}
+
+func (p *selectorParser) parseMatchValue(operator string) error {
+	token := p.peek()
+	if err := p.parseValue(); err != nil {
+		return err
+	}
+	if operator == "matches" {
+		if _, err := regexp.Compile(token.value); err != nil {
+			return fmt.Errorf("invalid regular expression at position %d: %s", token.position, err)
+		}
+	}
+	return nil
+}
+
+func (p *selectorParser) parseValue() error {
+	token := p.next()
+	switch token.kind {
+	case selectorTokenString, selectorTokenNumber:
+		return nil
+	case selectorTokenIdentifier:
+		if selectorKeywords[token.value] {
+			return fmt.Errorf("expected a value at position %d, got keyword %q", token.position, token.value)
+		}
+		return nil
+	}
+	return fmt.Errorf("expected a value at position %d, got %q", token.position, token.value)
+}
+
+func (p *selectorParser) parseSelectorPath() error {
+	var parts []string
+	for {
+		token := p.next()
+		if token.kind != selectorTokenIdentifier || selectorKeywords[token.value] {
+			return fmt.Errorf("expected a selector at position %d, got %q", token.position, token.value)
+		}
+		parts = append(parts, token.value)
+
+		if p.peek().kind != selectorTokenDot {
+			break
+		}
+		p.next()
+	}
+
+	p.selectors = append(p.selectors, strings.Join(parts, "."))
+	return nil
+}